@@ -0,0 +1,62 @@
+package gohtx
+
+// AriaAttrs is a slice of string containing the names of all valid aria-*
+// attributes. The names are taken from the WAI-ARIA 1.2 specification at
+// https://www.w3.org/TR/wai-aria-1.2/#state_prop_def and the ARIA 1.3 draft.
+// Unlike data-* attributes, aria-* names are fixed, so misspellings like
+// aria-lable are reported.
+var AriaAttrs []string = []string{
+	"aria-activedescendant",
+	"aria-atomic",
+	"aria-autocomplete",
+	"aria-braillelabel",
+	"aria-brailleroledescription",
+	"aria-busy",
+	"aria-checked",
+	"aria-colcount",
+	"aria-colindex",
+	"aria-colindextext",
+	"aria-colspan",
+	"aria-controls",
+	"aria-current",
+	"aria-describedby",
+	"aria-description",
+	"aria-details",
+	"aria-disabled",
+	"aria-dropeffect", // deprecated
+	"aria-errormessage",
+	"aria-expanded",
+	"aria-flowto",
+	"aria-grabbed", // deprecated
+	"aria-haspopup",
+	"aria-hidden",
+	"aria-invalid",
+	"aria-keyshortcuts",
+	"aria-label",
+	"aria-labelledby",
+	"aria-level",
+	"aria-live",
+	"aria-modal",
+	"aria-multiline",
+	"aria-multiselectable",
+	"aria-orientation",
+	"aria-owns",
+	"aria-placeholder",
+	"aria-posinset",
+	"aria-pressed",
+	"aria-readonly",
+	"aria-relevant",
+	"aria-required",
+	"aria-roledescription",
+	"aria-rowcount",
+	"aria-rowindex",
+	"aria-rowindextext",
+	"aria-rowspan",
+	"aria-selected",
+	"aria-setsize",
+	"aria-sort",
+	"aria-valuemax",
+	"aria-valuemin",
+	"aria-valuenow",
+	"aria-valuetext",
+}
@@ -44,6 +44,15 @@ func TestCheckAttr(t *testing.T) {
 		{"data-hx-morble", "button", errors.New("data-hx-morble doesn't match any valid htmx attribute")},
 		{"data-fooBar", "body", errors.New("data-fooBar: uppercase is not allowed in data-* attributes")},
 		{"data-", "body", errors.New("data- is not a valid html5 attribute")},
+		{"aria-label", "nav", nil},
+		{"aria-describedby", "input", nil},
+		{"aria-Label", "nav", errors.New("aria-Label is not a valid aria attribute")},
+		{"aria-lable", "nav", errors.New("aria-lable is not a valid aria attribute")},
+		{"aria-", "nav", errors.New("aria- is not a valid aria attribute")},
+		{"role", "div", nil},
+		{"role", "span", nil},
+		{"type", "link", nil}, // e.g. rel="stylesheet" type="text/css"
+		{"type", "div", errors.New("type is not a valid attribute for div")},
 	}
 	for _, test := range table {
 		err := checkAttr(test.tag, test.a)
@@ -2,9 +2,8 @@ package gohtx
 
 import (
 	"fmt"
+	"html/template"
 	"strings"
-
-	"golang.org/x/net/html"
 )
 
 // Attributes is filled in at init time with a list of attributes and the tag
@@ -22,13 +21,20 @@ func checkAttr(tag string, a string) error {
 			return fmt.Errorf("%s is not a valid html5 attribute", a)
 		case name != strings.ToLower(name):
 			return fmt.Errorf("%s: uppercase is not allowed in data-* attributes", a)
-		case strings.HasPrefix(name, "hx") && !isValidHxAttribute(name):
+		case strings.HasPrefix(name, "hx") && !isValidHxAttribute(name) && !isExtensionAttribute(name):
 			return fmt.Errorf("%s doesn't match any valid htmx attribute", a)
 		default:
 			return nil
 		}
 	}
-	if isValidHxAttribute(a) {
+	// aria-* attributes are valid on any element, see AriaAttrs
+	if strings.HasPrefix(a, "aria-") {
+		if !stringInSlice(a, AriaAttrs) {
+			return fmt.Errorf("%s is not a valid aria attribute", a)
+		}
+		return nil
+	}
+	if isValidHxAttribute(a) || isExtensionAttribute(a) {
 		return nil
 	}
 
@@ -49,6 +55,12 @@ func isValidHxAttribute(a string) bool {
 	return stringInSlice(a, HxAttrs)
 }
 
+// isExtensionAttribute reports whether a belongs to an enabled htmx extension.
+func isExtensionAttribute(a string) bool {
+	_, found := extensionAttribute(a)
+	return found
+}
+
 // stringInSlice returns true if string s is an element in slice ss.
 func stringInSlice(s string, ss []string) bool {
 	for _, v := range ss {
@@ -71,32 +83,65 @@ func stringInSlice(s string, ss []string) bool {
 //	return
 //}
 
-// checkTagAttributes builds an html string containing tag with attrs and
-// parses it, calling checkAttr on each attribute found. It returns a slice of
-// errors found. The slice will be empty if no errors where detected. There is
-// a separate err return that should be checked. It will be nil unless the
-// attrs string is so malformed that it can't be parsed.
+// checkTagAttributes tokenizes attrs as the attributes of tag, checking the
+// name and value of each attribute found. It returns a slice of errors found. The
+// slice will be empty if no errors where detected. There is a separate err
+// return that should be checked. It will be nil unless the attrs string is so
+// malformed that it can't be parsed.
 func checkTagAttributes(tag, attrs string) (errs []error, err error) {
-	// s := `<a href="foo" id="fooid" checked>Foo</a>`
-	s := fmt.Sprintf("<%s %s></%s>", tag, attrs, tag)
-	doc, err := html.Parse(strings.NewReader(s))
+	if tag == "!--" {
+		return // Comment stores its text in the attribute string.
+	}
+	parsed, err := parseAttributes(attrs)
 	if err != nil {
 		return
 	}
-	var f func(*html.Node)
-	f = func(n *html.Node) {
-		if n.Type == html.ElementNode {
-			for _, a := range n.Attr {
-				if err := checkAttr(tag, a.Key); err != nil {
-					errs = append(errs, err)
-				}
-			}
+	errs = checkAttributeList(tag, parsed)
+	return
+}
+
+// checkAttributeList calls checkAttr on each attribute in attrs, and
+// checkAttrValue on each valid one, and returns the errors found.
+func checkAttributeList(tag string, attrs []Attribute) (errs []error) {
+	for _, a := range attrs {
+		if err := checkAttr(tag, a.Name); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := checkAttrValue(tag, a.Name, a.Value); err != nil {
+			errs = append(errs, err)
 		}
-		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			f(c)
+	}
+	return
+}
+
+// tagAttributes returns the attribute string to be written in the opening
+// tag of h along with any errors found in the attributes. If h has no
+// structured attributes, the string is h.A unchanged. Otherwise it's the
+// merger of h.A and the structured attributes.
+func tagAttributes(h *HtmlTree) (attrs string, errs []error, err error) {
+	structured, err := structuredAttributes(h)
+	if err != nil {
+		return
+	}
+	if len(structured) == 0 {
+		if len(h.A) > 0 {
+			errs, err = checkTagAttributes(h.T, h.A)
 		}
+		attrs = h.A
+		return
+	}
+	if h.T == "null" {
+		err = fmt.Errorf("the null tag may not have attributes")
+		return
+	}
+	legacy, err := parseAttributes(h.A)
+	if err != nil {
+		return
 	}
-	f(doc)
+	merged := mergeAttributes(legacy, structured)
+	errs = checkAttributeList(h.T, merged)
+	attrs = formatAttributes(merged)
 	return
 }
 
@@ -110,17 +155,18 @@ type AttributeErrors struct {
 }
 
 // CheckAttributes walks through an ElementTree and checks each tag to verify that
-// the attribute names associated with the tag are valid for that tag. It returns a slice
+// the attribute names associated with the tag are valid for that tag and that
+// their values match the grammars in AttributeValues. It returns a slice
 // AttributeErrors. The slice will be empty if no errors were found.
 func (e *HtmlTree) CheckAttributes(perrs *[]AttributeErrors) {
-	errslice, _ := checkTagAttributes(e.T, e.A)
+	attrs, errslice, _ := tagAttributes(e)
 	if len(errslice) != 0 {
-		*perrs = append(*perrs, AttributeErrors{e.T, e.A, errslice})
+		*perrs = append(*perrs, AttributeErrors{e.T, attrs, errslice})
 	}
 	for _, c := range e.C {
 		switch t := c.(type) {
-		case string:
-			continue // nothing to do for string content
+		case string, Raw, template.HTML, Attributer:
+			continue // nothing to do for text content or attributes
 		case *HtmlTree:
 			c.(*HtmlTree).CheckAttributes(perrs)
 		default:
@@ -199,6 +245,7 @@ func init() {
 		"language":        {"script"},
 		"lazyload":        {"img", "iframe"},
 		"list":            {"input"},
+		"loading":         {"img", "iframe"},
 		"loop":            {"audio", "bgsound", "marquee", "video"},
 		"low":             {"meter"},
 		"manifest":        {"html"},
@@ -211,6 +258,7 @@ func init() {
 		"multiple":        {"input", "select"},
 		"muted":           {"audio", "video"},
 		"name":            {"button", "form", "fieldset", "iframe", "input", "keygen", "object", "output", "select", "textarea", "map", "meta", "param"},
+		"nonce":           {"*"},
 		"novalidate":      {"form"},
 		"open":            {"details"},
 		"optimum":         {"meter"},
@@ -219,9 +267,11 @@ func init() {
 		"placeholder":     {"input", "textarea"},
 		"poster":          {"video"},
 		"preload":         {"audio", "video"},
+		"property":        {"meta"}, // RDFa, used by Open Graph
 		"radiogroup":      {"command"},
 		"readonly":        {"input", "textarea"},
 		"rel":             {"a", "area", "link"},
+		"role":            {"*"},
 		"required":        {"input", "select", "textarea"},
 		"reversed":        {"ol"},
 		"rows":            {"textarea"},
@@ -248,7 +298,7 @@ func init() {
 		"target":          {"a", "area", "base", "form"},
 		"title":           {"*"},
 		"translate":       {"*"},
-		"type":            {"button", "input", "command", "embed", "object", "script", "source", "style", "menu"},
+		"type":            {"button", "input", "command", "embed", "link", "object", "script", "source", "style", "menu"}, // link for the type="text/css" of the head content's stylesheets
 		"usemap":          {"img", "input", "object"},
 		"value":           {"button", "option", "input", "li", "meter", "progress", "param"},
 		"width":           {"canvas", "embed", "iframe", "img", "input", "object", "video"},
@@ -0,0 +1,201 @@
+package gohtx
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+
+	nethtml "golang.org/x/net/html"
+)
+
+// Attribute is a single html attribute stored as a name/value pair. Values are
+// quoted and escaped when rendered, so they may safely contain quotes, JSON or
+// text supplied by users.
+type Attribute struct {
+	Name  string
+	Value string
+	Bool  bool // true for boolean attributes like 'checked' that render without a value
+}
+
+// Attrs is a map of attribute names to values, e.g. Attrs{"id": "x"}. Render
+// writes them in name order.
+type Attrs map[string]string
+
+// Attributer is implemented by content that contributes attributes to the
+// element that contains it instead of content. Attribute, Attrs and the
+// values returned by Class, Data and BoolAttr are all Attributers. Pass them
+// to a tag function along with other content,
+//
+//	Div(`class=box`, Attrs{"id": "x"}, Class("is-primary"), "text")
+//
+// or attach them with HtmlTree.With. Attributers may be mixed freely with the
+// legacy attribute string. The class lists of all sources are merged. For
+// other attributes, a structured value replaces any earlier value with the
+// same name.
+type Attributer interface {
+	Attributes() ([]Attribute, error)
+}
+
+// Attributes implements Attributer.
+func (a Attribute) Attributes() ([]Attribute, error) {
+	return []Attribute{a}, nil
+}
+
+// Attributes implements Attributer.
+func (a Attrs) Attributes() ([]Attribute, error) {
+	names := make([]string, 0, len(a))
+	for name := range a {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	attrs := make([]Attribute, 0, len(a))
+	for _, name := range names {
+		attrs = append(attrs, Attribute{Name: name, Value: a[name]})
+	}
+	return attrs, nil
+}
+
+// Attr returns an Attribute with the given name and value.
+func Attr(name, value string) Attribute {
+	return Attribute{Name: name, Value: value}
+}
+
+// BoolAttr returns a boolean Attribute, e.g. BoolAttr("checked").
+func BoolAttr(name string) Attribute {
+	return Attribute{Name: name, Bool: true}
+}
+
+// Class returns a class Attribute listing names. Empty names are ignored, so
+// Class("button", maybe) works when maybe is "".
+func Class(names ...string) Attribute {
+	return Attribute{Name: "class", Value: strings.Join(strings.Fields(strings.Join(names, " ")), " ")}
+}
+
+// Data returns a data-* Attribute, e.g. Data("user-id", "42") renders as
+// data-user-id="42".
+func Data(key, value string) Attribute {
+	return Attribute{Name: "data-" + key, Value: value}
+}
+
+// With appends Attributers to h and returns h. It's the way to add structured
+// attributes to empty tags, which take no content arguments, e.g.
+// Input(`type=text`).With(Attrs{"name": name, "value": value}).
+func (h *HtmlTree) With(a ...Attributer) *HtmlTree {
+	for _, v := range a {
+		h.C = append(h.C, v)
+	}
+	return h
+}
+
+// structuredAttributes returns the attributes contributed by Attributer
+// values in the content of h.
+func structuredAttributes(h *HtmlTree) (attrs []Attribute, err error) {
+	for _, c := range h.C {
+		a, ok := c.(Attributer)
+		if !ok {
+			continue
+		}
+		more, err := a.Attributes()
+		if err != nil {
+			return nil, fmt.Errorf("%s : %v", h.T, err)
+		}
+		attrs = append(attrs, more...)
+	}
+	return
+}
+
+// parseAttributes tokenizes a legacy attribute string into a slice of
+// Attribute. It returns an error if attrs is too malformed to tokenize.
+func parseAttributes(attrs string) ([]Attribute, error) {
+	z := nethtml.NewTokenizer(strings.NewReader("<div " + attrs + ">"))
+	if z.Next() == nethtml.ErrorToken {
+		return nil, fmt.Errorf("can't parse attributes '%s': %v", attrs, z.Err())
+	}
+	var parsed []Attribute
+	for _, a := range z.Token().Attr {
+		parsed = append(parsed, Attribute{Name: a.Key, Value: a.Val, Bool: a.Val == ""})
+	}
+	return parsed, nil
+}
+
+// mergeAttributes returns the legacy attributes followed by the structured
+// ones. All class values are merged, without duplicates, into the first class
+// attribute. Any other structured attribute replaces an earlier attribute with
+// the same name.
+func mergeAttributes(legacy, structured []Attribute) (merged []Attribute) {
+	index := make(map[string]int) // name -> position in merged
+	for _, a := range append(append([]Attribute{}, legacy...), structured...) {
+		i, seen := index[a.Name]
+		switch {
+		case !seen:
+			index[a.Name] = len(merged)
+			merged = append(merged, a)
+		case a.Name == "class":
+			merged[i].Value = mergeClasses(merged[i].Value, a.Value)
+			merged[i].Bool = merged[i].Value == ""
+		default:
+			merged[i] = a
+		}
+	}
+	return
+}
+
+// mergeClasses appends the class names in b that are not already in a.
+func mergeClasses(a, b string) string {
+	names := strings.Fields(a)
+	for _, name := range strings.Fields(b) {
+		if !stringInSlice(name, names) {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, " ")
+}
+
+// formatAttributes returns attrs as a string suitable for an opening tag. The
+// values are double-quoted and escaped.
+func formatAttributes(attrs []Attribute) string {
+	var sb strings.Builder
+	for i, a := range attrs {
+		if i > 0 {
+			sb.WriteString(" ")
+		}
+		sb.WriteString(a.Name)
+		if a.Bool && a.Value == "" {
+			continue
+		}
+		sb.WriteString(`="`)
+		sb.WriteString(html.EscapeString(a.Value))
+		sb.WriteString(`"`)
+	}
+	return sb.String()
+}
+
+// elementAttributes returns the attributes of h, parsed from h.A and merged
+// with any structured attributes.
+func elementAttributes(h *HtmlTree) ([]Attribute, error) {
+	structured, err := structuredAttributes(h)
+	if err != nil {
+		return nil, err
+	}
+	legacy, err := parseAttributes(h.A)
+	if err != nil {
+		return nil, err
+	}
+	return mergeAttributes(legacy, structured), nil
+}
+
+// attributeValue returns the value of the named attribute of h and whether
+// the attribute is present.
+func attributeValue(h *HtmlTree, name string) (value string, found bool) {
+	attrs, err := elementAttributes(h)
+	if err != nil {
+		return
+	}
+	for _, a := range attrs {
+		if a.Name == name {
+			return a.Value, true
+		}
+	}
+	return
+}
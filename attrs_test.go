@@ -0,0 +1,73 @@
+package gohtx
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestStructuredAttributes(t *testing.T) {
+	type items struct {
+		e   *HtmlTree //what to render
+		exp string    // expected result
+	}
+	table := []items{
+		{Div(``, Attrs{"id": "x"}, "text"), `<div id="x">text</div>`},
+		{Div(`class=box`, Attrs{"id": "x", "hx-vals": `{"key": "a<b"}`}, Class("is-primary", "box"), "text"),
+			`<div class="box is-primary" hx-vals="{&#34;key&#34;: &#34;a&lt;b&#34;}" id="x">text</div>`},
+		{P(`id=old title='it'`, Attr("id", "new")), `<p id="new" title="it"></p>`},
+		{Span(``, Data("user-id", `"42"`)), `<span data-user-id="&#34;42&#34;"></span>`},
+		{Span(``, Class("", "a", " b ")), `<span class="a b"></span>`},
+		{Input(`type=text`).With(Attrs{"name": "q"}, BoolAttr("required")), `<input type="text" name="q" required>`},
+		{Td(`colspan=2`, Class("x")), `<td colspan="2" class="x"></td>`},
+	}
+	for _, test := range table {
+		var b bytes.Buffer
+		err := Render(test.e, &b, -1)
+		if err != nil {
+			t.Errorf("Render failed: %v", err)
+		}
+		r := b.String()
+		if r != test.exp {
+			t.Errorf("Expected %s, got %s", test.exp, r)
+		}
+	}
+	// These cases should return errors
+	ecases := []*HtmlTree{
+		Div(``, Attr("href", "/foo")),
+		Null(Attrs{"id": "x"}),
+	}
+	for _, e := range ecases {
+		var b bytes.Buffer
+		if err := Render(e, &b, -1); err == nil {
+			t.Errorf("expected an error rendering %s, got nil", b.String())
+		}
+	}
+}
+
+func TestStructuredIds(t *testing.T) {
+	ids := []string{}
+	tree := Div(``, Attrs{"id": "outer"}, P(`id=old`, Attr("id", "inner")))
+	if err := Ids(tree, &ids); err != nil {
+		t.Errorf("%v", err)
+	}
+	if diff := deep.Equal(ids, []string{"outer", "inner"}); diff != nil {
+		t.Errorf("%v", diff)
+	}
+	ids = []string{}
+	if err := Ids(Div(``, Attr("id", "")), &ids); err == nil {
+		t.Errorf("expected an error for an empty id, got nil")
+	}
+}
+
+func TestCheckStructuredAttributes(t *testing.T) {
+	perrs := &[]AttributeErrors{}
+	Body(``, Div(`class=a`, Attr("href", "/foo"))).CheckAttributes(perrs)
+	if len(*perrs) != 1 {
+		t.Fatalf("expected 1 AttributeErrors, got %v", *perrs)
+	}
+	if exp := `class="a" href="/foo"`; (*perrs)[0].Attrs != exp {
+		t.Errorf("expected Attrs %s, got %s", exp, (*perrs)[0].Attrs)
+	}
+}
@@ -0,0 +1,273 @@
+package gohtx
+
+import (
+	"fmt"
+	"mime"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ValueGrammar checks the value of the named attribute. It returns an error
+// explaining why the value is invalid or nil if it's valid.
+type ValueGrammar func(name, value string) error
+
+// AttributeValues is filled in at init time with the grammars of attribute
+// values, keyed first by attribute name and then by tag name. The tag "*"
+// applies to all tags without an entry of their own. Attributes that aren't
+// listed accept any value.
+var AttributeValues map[string]map[string]ValueGrammar
+
+// checkAttrValue tests the value of attribute a in the context of a
+// particular tag. Errors name the attribute as written, e.g. data-hx-swap.
+func checkAttrValue(tag, a, value string) error {
+	name := a
+	if strings.HasPrefix(name, "data-hx-") {
+		name = name[5:] // data-hx-* values follow the hx-* grammars
+	}
+	// Html grammars come first. Attributes without one for this tag, like
+	// preload on an <a>, may belong to an enabled extension.
+	grammars := AttributeValues[name]
+	grammar, found := grammars[tag]
+	if !found {
+		grammar, found = grammars["*"]
+	}
+	if !found {
+		grammar, _ = extensionAttribute(name)
+	}
+	if grammar == nil {
+		return nil
+	}
+	if err := grammar(name, value); err != nil {
+		return fmt.Errorf("invalid value %q for attribute %s of %s: %v", value, a, tag, err)
+	}
+	return nil
+}
+
+// EnumValue returns a grammar that accepts any of values, ignoring case as html
+// does for enumerated attributes.
+func EnumValue(values ...string) ValueGrammar {
+	return func(name, value string) error {
+		for _, v := range values {
+			if strings.EqualFold(value, v) {
+				return nil
+			}
+		}
+		var quoted []string
+		for _, v := range values {
+			quoted = append(quoted, strconv.Quote(v))
+		}
+		return fmt.Errorf("must be one of %s", strings.Join(quoted, ", "))
+	}
+}
+
+// AnyOfValues returns a grammar that accepts values accepted by any of grammars.
+// It reports the error from the last grammar when none of them accepts it.
+func AnyOfValues(grammars ...ValueGrammar) ValueGrammar {
+	return func(name, value string) (err error) {
+		for _, g := range grammars {
+			if err = g(name, value); err == nil {
+				return
+			}
+		}
+		return
+	}
+}
+
+// TokenListValue returns a grammar for a list of space-separated tokens, each of
+// which must be accepted by grammar.
+func TokenListValue(grammar ValueGrammar) ValueGrammar {
+	return func(name, value string) error {
+		for _, token := range strings.Fields(value) {
+			if err := grammar(name, token); err != nil {
+				return fmt.Errorf("token %q: %v", token, err)
+			}
+		}
+		return nil
+	}
+}
+
+// BooleanValue accepts the values permitted for boolean attributes: the empty
+// string or the attribute name itself.
+func BooleanValue(name, value string) error {
+	if value == "" || strings.EqualFold(value, name) {
+		return nil
+	}
+	return fmt.Errorf("boolean attributes must be empty or %q", name)
+}
+
+// IntegerValue accepts a valid html integer, e.g. "-3".
+func IntegerValue(name, value string) error {
+	if _, err := strconv.ParseInt(value, 10, 64); err != nil || strings.HasPrefix(value, "+") {
+		return fmt.Errorf("must be an integer")
+	}
+	return nil
+}
+
+// NonNegativeIntegerValue accepts an integer that's 0 or greater.
+func NonNegativeIntegerValue(name, value string) error {
+	if n, err := strconv.ParseInt(value, 10, 64); err != nil || n < 0 || strings.HasPrefix(value, "+") {
+		return fmt.Errorf("must be a non-negative integer")
+	}
+	return nil
+}
+
+// PositiveIntegerValue accepts an integer that's 1 or greater.
+func PositiveIntegerValue(name, value string) error {
+	if n, err := strconv.ParseInt(value, 10, 64); err != nil || n < 1 || strings.HasPrefix(value, "+") {
+		return fmt.Errorf("must be a positive integer")
+	}
+	return nil
+}
+
+// NumberValue accepts a valid floating-point number, e.g. "1.5e3".
+func NumberValue(name, value string) error {
+	if _, err := strconv.ParseFloat(value, 64); err != nil || strings.HasPrefix(value, "+") || strings.HasSuffix(value, ".") {
+		return fmt.Errorf("must be a number")
+	}
+	return nil
+}
+
+// URLValue accepts a URL, absolute or relative, that can be parsed by
+// net/url. Leading and trailing spaces are permitted as html strips them.
+func URLValue(name, value string) error {
+	if _, err := url.Parse(strings.TrimSpace(value)); err != nil {
+		return fmt.Errorf("must be a valid URL")
+	}
+	return nil
+}
+
+// MIMETypeValue accepts a media type with optional parameters, e.g.
+// "text/html; charset=utf-8".
+func MIMETypeValue(name, value string) error {
+	mediatype, _, err := mime.ParseMediaType(value)
+	if err != nil || !strings.Contains(mediatype, "/") {
+		return fmt.Errorf("must be a MIME type")
+	}
+	return nil
+}
+
+// IDValue accepts a non-empty value with no whitespace.
+func IDValue(name, value string) error {
+	if value == "" || strings.ContainsAny(value, " \t\n\f\r") {
+		return fmt.Errorf("must be non-empty and contain no whitespace")
+	}
+	return nil
+}
+
+// acceptList accepts the value of the accept attribute, a comma-separated list
+// of file extensions, MIME types and wildcards like "image/*".
+func acceptList(name, value string) error {
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		switch {
+		case strings.HasPrefix(item, ".") && len(item) > 1:
+		case stringInSlice(strings.ToLower(item), []string{"audio/*", "video/*", "image/*"}):
+		case MIMETypeValue(name, item) == nil:
+		default:
+			return fmt.Errorf("%q is not a file extension or MIME type", item)
+		}
+	}
+	return nil
+}
+
+func init() {
+	boolean := map[string]ValueGrammar{"*": BooleanValue}
+	all := func(g ValueGrammar) map[string]ValueGrammar {
+		return map[string]ValueGrammar{"*": g}
+	}
+	// preload on other elements belongs to the htmx preload extension.
+	mediaPreload := EnumValue("", "none", "metadata", "auto")
+	// Derived from the attribute definitions at
+	// https://developer.mozilla.org/en-US/docs/Web/HTML/Attributes and
+	// the element pages linked from it.
+	AttributeValues = map[string]map[string]ValueGrammar{
+		"accept":          all(acceptList),
+		"action":          all(URLValue),
+		"async":           boolean,
+		"autocapitalize":  all(EnumValue("off", "none", "on", "sentences", "words", "characters")),
+		"autocomplete":    {"form": EnumValue("on", "off")},
+		"autofocus":       boolean,
+		"autoplay":        boolean,
+		"charset":         {"meta": EnumValue("utf-8")},
+		"checked":         boolean,
+		"cite":            all(URLValue),
+		"cols":            all(PositiveIntegerValue),
+		"colspan":         all(PositiveIntegerValue),
+		"contenteditable": all(EnumValue("", "true", "false", "plaintext-only")),
+		"controls":        boolean,
+		"crossorigin":     all(EnumValue("", "anonymous", "use-credentials")),
+		"data":            {"object": URLValue},
+		"decoding":        all(EnumValue("sync", "async", "auto")),
+		"default":         boolean,
+		"defer":           boolean,
+		"dir":             all(EnumValue("ltr", "rtl", "auto")),
+		"disabled":        boolean,
+		"draggable":       all(EnumValue("true", "false")),
+		"enctype":         all(EnumValue("application/x-www-form-urlencoded", "multipart/form-data", "text/plain")),
+		"formaction":      all(URLValue),
+		"height":          all(NonNegativeIntegerValue),
+		"hidden":          all(AnyOfValues(BooleanValue, EnumValue("until-found"))),
+		"high":            all(NumberValue),
+		"href":            all(URLValue),
+		"http-equiv":      all(EnumValue("content-security-policy", "content-type", "default-style", "x-ua-compatible", "refresh")),
+		"id":              all(IDValue),
+		"importance":      all(EnumValue("auto", "high", "low")),
+		"ismap":           boolean,
+		"kind":            all(EnumValue("subtitles", "captions", "descriptions", "chapters", "metadata")),
+		"loading":         all(EnumValue("eager", "lazy")),
+		"loop":            boolean,
+		"low":             all(NumberValue),
+		"max":             {"meter": NumberValue, "progress": NumberValue},
+		"maxlength":       all(NonNegativeIntegerValue),
+		"method":          all(EnumValue("get", "post", "dialog")),
+		"min":             {"meter": NumberValue},
+		"minlength":       all(NonNegativeIntegerValue),
+		"multiple":        boolean,
+		"muted":           boolean,
+		"novalidate":      boolean,
+		"open":            boolean,
+		"optimum":         all(NumberValue),
+		"ping":            all(TokenListValue(URLValue)),
+		"poster":          all(URLValue),
+		"preload":         {"audio": mediaPreload, "video": mediaPreload},
+		"readonly":        boolean,
+		"required":        boolean,
+		"reversed":        boolean,
+		"rows":            all(PositiveIntegerValue),
+		"rowspan":         all(NonNegativeIntegerValue),
+		"sandbox": all(TokenListValue(EnumValue("allow-downloads", "allow-forms", "allow-modals", "allow-orientation-lock",
+			"allow-pointer-lock", "allow-popups", "allow-popups-to-escape-sandbox", "allow-presentation",
+			"allow-same-origin", "allow-scripts", "allow-top-navigation", "allow-top-navigation-by-user-activation",
+			"allow-top-navigation-to-custom-protocols"))),
+		"scope":      all(EnumValue("row", "col", "rowgroup", "colgroup", "auto")),
+		"selected":   boolean,
+		"shape":      all(EnumValue("rect", "circle", "poly", "default")),
+		"size":       all(PositiveIntegerValue),
+		"span":       all(PositiveIntegerValue),
+		"spellcheck": all(EnumValue("", "true", "false")),
+		"src":        all(URLValue),
+		"start":      all(IntegerValue),
+		"step":       {"input": AnyOfValues(EnumValue("any"), NumberValue)},
+		"tabindex":   all(IntegerValue),
+		"translate":  all(EnumValue("", "yes", "no")),
+		"type": {
+			"button": EnumValue("submit", "reset", "button"),
+			"embed":  MIMETypeValue,
+			"input": EnumValue("button", "checkbox", "color", "date", "datetime-local", "email", "file", "hidden",
+				"image", "month", "number", "password", "radio", "range", "reset", "search", "submit", "tel",
+				"text", "time", "url", "week"),
+			"link":   MIMETypeValue,
+			"object": MIMETypeValue,
+			"script": AnyOfValues(EnumValue("module", "importmap"), MIMETypeValue),
+			"source": MIMETypeValue,
+			"style":  MIMETypeValue,
+		},
+		"value": {"meter": NumberValue, "progress": NumberValue, "li": IntegerValue},
+		"width": all(NonNegativeIntegerValue),
+		"wrap":  all(EnumValue("hard", "soft", "off")),
+	}
+	for name, g := range hxValueGrammars {
+		AttributeValues[name] = all(g)
+	}
+}
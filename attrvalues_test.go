@@ -0,0 +1,69 @@
+package gohtx
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCheckAttrValue(t *testing.T) {
+	type items struct {
+		tag, a, v string // tag, attribute and value
+		exp       string // expected error or "" for none
+	}
+	table := []items{
+		{"input", "type", "text", ""},
+		{"input", "type", "EMAIL", ""},
+		{"input", "type", "bogus", `invalid value "bogus" for attribute type of input: must be one of "button", "checkbox", "color", "date", "datetime-local", "email", "file", "hidden", "image", "month", "number", "password", "radio", "range", "reset", "search", "submit", "tel", "text", "time", "url", "week"`},
+		{"button", "type", "submit", ""},
+		{"script", "type", "module", ""},
+		{"script", "type", "text/javascript", ""},
+		{"link", "type", "text css", `invalid value "text css" for attribute type of link: must be a MIME type`},
+		{"div", "data-hx-swap", "bogus", `invalid value "bogus" for attribute data-hx-swap of div: unknown swap style "bogus", expected one of innerHTML, outerHTML, beforebegin, afterbegin, beforeend, afterend, delete, none`},
+		{"div", "data-hx-swap", "outerHTML", ""},
+		{"form", "method", "post", ""},
+		{"form", "method", "fetch", `invalid value "fetch" for attribute method of form: must be one of "get", "post", "dialog"`},
+		{"img", "loading", "lazy", ""},
+		{"img", "loading", "sometimes", `invalid value "sometimes" for attribute loading of img: must be one of "eager", "lazy"`},
+		{"td", "colspan", "2", ""},
+		{"td", "colspan", "two", `invalid value "two" for attribute colspan of td: must be a positive integer`},
+		{"td", "colspan", "0", `invalid value "0" for attribute colspan of td: must be a positive integer`},
+		{"td", "rowspan", "0", ""},
+		{"input", "checked", "", ""},
+		{"input", "checked", "checked", ""},
+		{"input", "checked", "yes", `invalid value "yes" for attribute checked of input: boolean attributes must be empty or "checked"`},
+		{"a", "href", "/foo?x=1#top", ""},
+		{"a", "href", "http://[::1", `invalid value "http://[::1" for attribute href of a: must be a valid URL`},
+		{"input", "accept", ".pdf, image/*,text/plain", ""},
+		{"input", "accept", "pdf", `invalid value "pdf" for attribute accept of input: "pdf" is not a file extension or MIME type`},
+		{"iframe", "sandbox", "allow-forms allow-scripts", ""},
+		{"iframe", "sandbox", "allow-forms allow-everything", `invalid value "allow-forms allow-everything" for attribute sandbox of iframe: token "allow-everything": must be one of "allow-downloads", "allow-forms", "allow-modals", "allow-orientation-lock", "allow-pointer-lock", "allow-popups", "allow-popups-to-escape-sandbox", "allow-presentation", "allow-same-origin", "allow-scripts", "allow-top-navigation", "allow-top-navigation-by-user-activation", "allow-top-navigation-to-custom-protocols"`},
+		{"meter", "value", "0.5", ""},
+		{"meter", "value", "half", `invalid value "half" for attribute value of meter: must be a number`},
+		{"input", "value", "anything goes", ""},
+		{"input", "step", "any", ""},
+		{"div", "id", "a b", `invalid value "a b" for attribute id of div: must be non-empty and contain no whitespace`},
+		{"div", "tabindex", "-1", ""},
+	}
+	for _, test := range table {
+		err := checkAttrValue(test.tag, test.a, test.v)
+		switch {
+		case err == nil && test.exp != "":
+			t.Errorf("Expected %s got nil.", test.exp)
+		case err != nil && err.Error() != test.exp:
+			t.Errorf("Expected %q got %q.", test.exp, err)
+		}
+	}
+}
+
+func TestRenderReportsInvalidValues(t *testing.T) {
+	var b bytes.Buffer
+	err := Render(Form(`method=fetch`, Input(`type=bogus`)), &b, -1)
+	if err == nil {
+		t.Errorf("expected an error, got nil")
+	}
+	perrs := &[]AttributeErrors{}
+	Form(`method=fetch`, Input(``).With(Attr("type", "bogus"))).CheckAttributes(perrs)
+	if len(*perrs) != 2 {
+		t.Errorf("expected errors for form and input, got %v", *perrs)
+	}
+}
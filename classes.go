@@ -0,0 +1,190 @@
+package gohtx
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// knownClasses holds the class names CheckClasses accepts. The classes used
+// by the embedded Bulma and the classes htmx adds while it works are loaded
+// the first time a class is checked, so programs that never check classes
+// don't pay for parsing Bulma. It grows with RegisterStylesheet and
+// RegisterClasses.
+var knownClasses = struct {
+	sync.RWMutex
+	once  sync.Once
+	names map[string]bool
+}{names: make(map[string]bool)}
+
+// bulmaMarkupClasses are classes that Bulma's documented markup uses but its
+// stylesheet doesn't style.
+var bulmaMarkupClasses = []string{"dropdown-trigger"}
+
+// htmxClasses are the classes htmx adds to elements, and that pages style,
+// e.g. htmx-indicator.
+var htmxClasses = []string{"htmx-added", "htmx-indicator", "htmx-request", "htmx-settling", "htmx-swapping"}
+
+// loadKnownClasses adds the classes of the embedded Bulma and of htmx to
+// knownClasses the first time it's called.
+func loadKnownClasses() {
+	knownClasses.once.Do(func() {
+		css, err := staticFiles.ReadFile("bulma/css/bulma.css")
+		if err != nil {
+			// The FS is compiled in, so failure is a programming error.
+			panic(err)
+		}
+		RegisterStylesheet(string(css))
+		RegisterClasses(bulmaMarkupClasses...)
+		RegisterClasses(htmxClasses...)
+	})
+}
+
+// RegisterStylesheet makes the classes defined by the selectors in css valid
+// for CheckClasses. Register your application's stylesheets, and those of
+// any CSS libraries you load alongside Bulma, e.g. an icon font.
+func RegisterStylesheet(css string) {
+	RegisterClasses(StylesheetClasses(css)...)
+}
+
+// RegisterClasses makes names valid for CheckClasses. Use it for classes no
+// stylesheet defines, like the ones scripts look for.
+func RegisterClasses(names ...string) {
+	knownClasses.Lock()
+	defer knownClasses.Unlock()
+	for _, name := range names {
+		knownClasses.names[name] = true
+	}
+}
+
+// isKnownClass reports whether name is defined by Bulma or registered.
+func isKnownClass(name string) bool {
+	loadKnownClasses()
+	knownClasses.RLock()
+	defer knownClasses.RUnlock()
+	return knownClasses.names[name]
+}
+
+// CheckClasses walks through an HtmlTree and checks that each class name in
+// its class attributes is defined by the embedded Bulma or by a stylesheet
+// registered with RegisterStylesheet, so typos like is-primay or colums are
+// reported. Like CheckAttributes, it appends the errors it finds for each
+// element to perrs. Render doesn't check classes, so call it from your tests.
+//
+// The embedded bulma.css is parsed the first time CheckClasses is called,
+// not at init, so programs that never check classes don't pay for it. Later
+// calls reuse the result.
+func (e *HtmlTree) CheckClasses(perrs *[]AttributeErrors) {
+	if e.T != "!--" {
+		attrs, errs := classErrors(e)
+		if len(errs) != 0 {
+			*perrs = append(*perrs, AttributeErrors{e.T, attrs, errs})
+		}
+	}
+	for _, c := range e.C {
+		if t, ok := c.(*HtmlTree); ok {
+			t.CheckClasses(perrs)
+		}
+	}
+}
+
+// classErrors returns the attribute string of h and an error for each class
+// name in it that isn't known.
+func classErrors(h *HtmlTree) (attrs string, errs []error) {
+	attrs, _, err := tagAttributes(h)
+	if err != nil {
+		return attrs, []error{err}
+	}
+	parsed, err := parseAttributes(attrs)
+	if err != nil {
+		return attrs, []error{err}
+	}
+	for _, a := range parsed {
+		if a.Name != "class" {
+			continue
+		}
+		for _, name := range strings.Fields(a.Value) {
+			if !isKnownClass(name) {
+				errs = append(errs, fmt.Errorf("class %s is not defined by Bulma or a registered stylesheet", name))
+			}
+		}
+	}
+	return
+}
+
+// StylesheetClasses returns the sorted class names used in the selectors of
+// css. It skips comments, quoted strings, declarations and the preludes of
+// at-rules, so values like 1.5em and url(a.png) aren't mistaken for classes.
+func StylesheetClasses(css string) []string {
+	seen := make(map[string]bool)
+	var prelude strings.Builder
+	for i := 0; i < len(css); i++ {
+		switch c := css[i]; {
+		case c == '/' && strings.HasPrefix(css[i:], "/*"):
+			end := strings.Index(css[i+2:], "*/")
+			if end < 0 {
+				i = len(css)
+			} else {
+				i += end + 3
+			}
+		case c == '"' || c == '\'':
+			for i++; i < len(css) && css[i] != c; i++ {
+				if css[i] == '\\' {
+					i++
+				}
+			}
+		case c == '\\':
+			prelude.WriteByte(c)
+			if i+1 < len(css) {
+				i++
+				prelude.WriteByte(css[i])
+			}
+		case c == ';' || c == '}':
+			prelude.Reset()
+		case c == '{':
+			if p := strings.TrimSpace(prelude.String()); !strings.HasPrefix(p, "@") {
+				for _, name := range selectorClasses(p) {
+					seen[name] = true
+				}
+			}
+			prelude.Reset()
+		default:
+			prelude.WriteByte(c)
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// selectorClasses returns the class names in a selector list, unescaping
+// any backslash escapes in them.
+func selectorClasses(selector string) (names []string) {
+	for i := 0; i < len(selector); i++ {
+		if selector[i] != '.' || i+1 == len(selector) {
+			continue
+		}
+		// An identifier can't start with a digit, so .5 in a keyframe
+		// selector like 12.5% isn't a class.
+		if c := selector[i+1]; c >= '0' && c <= '9' {
+			continue
+		}
+		name, next := readIdent(selector, i+1)
+		i = next - 1 // let the loop see the next byte, which may start another class
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return
+}
+
+// isIdentByte reports whether c may appear in a CSS identifier. Bytes of
+// multi-byte UTF-8 characters are allowed.
+func isIdentByte(c byte) bool {
+	return c == '-' || c == '_' || c >= 0x80 ||
+		c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
@@ -0,0 +1,64 @@
+package gohtx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStylesheetClasses(t *testing.T) {
+	css := `
+/* .commented { } */
+.a, div.b > .c:hover, .d.e::after { content: ".not-a-class"; margin: 1.5em; }
+[href$=".pdf"].f { background: url(x.png); }
+@media screen and (min-width: 769px) { .g { width: 50%; } }
+@keyframes spin { 0% { opacity: 0; } 12.5% { opacity: .5; } to { opacity: 1; } }
+.is-1\.5 { }
+.h{}.i-j_k{}
+`
+	exp := []string{"a", "b", "c", "d", "e", "f", "g", "h", "i-j_k", "is-1.5"}
+	if got := StylesheetClasses(css); !reflect.DeepEqual(got, exp) {
+		t.Errorf("expected %v, got %v", exp, got)
+	}
+}
+
+func TestCheckClasses(t *testing.T) {
+	for _, name := range []string{"button", "is-primary", "columns", "column", "is-offset-1", "navbar-burger", "has-text-centered", "htmx-indicator"} {
+		if !isKnownClass(name) {
+			t.Errorf("expected %s to be known", name)
+		}
+	}
+
+	check := func(h *HtmlTree) (msgs []string) {
+		var perrs []AttributeErrors
+		h.CheckClasses(&perrs)
+		for _, e := range perrs {
+			for _, err := range e.Errs {
+				msgs = append(msgs, e.Tag+": "+err.Error())
+			}
+		}
+		return
+	}
+	h := Div(`class="colums"`,
+		Div(`class="column is-half"`),
+		Button(`class="button"`, Class("is-primay"), "Go"),
+		Comment(`class="whatever"`),
+	)
+	exp := []string{
+		"div: class colums is not defined by Bulma or a registered stylesheet",
+		"button: class is-primay is not defined by Bulma or a registered stylesheet",
+	}
+	if got := check(h); !reflect.DeepEqual(got, exp) {
+		t.Errorf("expected\n%s\ngot\n%s", strings.Join(exp, "\n"), strings.Join(got, "\n"))
+	}
+
+	h = Span(`class="test-icon test-flash"`)
+	if got := check(h); len(got) != 2 {
+		t.Errorf("expected 2 errors, got %v", got)
+	}
+	RegisterStylesheet(`.test-icon::before { content: "\f0e0"; }`)
+	RegisterClasses("test-flash")
+	if got := check(h); len(got) != 0 {
+		t.Errorf("expected no errors after registering, got %v", got)
+	}
+}
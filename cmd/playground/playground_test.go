@@ -47,10 +47,10 @@ func TestGetFragments(t *testing.T) {
 func TestMkSelect(t *testing.T) {
 	names := []string{"one", "two"}
 	s := mkSelect(names, "/url", "which", "#id")
-	exp := `<div class="field">` + `<label class="label">Examples</label>` +
+	exp := `<div class="field">` + `<label class="label" for="which">Examples</label>` +
 		`<div class="control">` +
 		`<div class="select is-link">` +
-		`<select name="which" hx-get="/url" hx-target="#id">` +
+		`<select name="which" hx-get="/url" hx-target="#id" id="which">` +
 		`<option value="one">one</option>` +
 		`<option value="two">two</option>` +
 		`</select></div></div></div>`
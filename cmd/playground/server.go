@@ -27,20 +27,20 @@ func Serve() {
 	// Declare HandlerFuncs
 
 	// The index page handler
-	http.Handle("/", http.HandlerFunc(indexHndlr))
+	http.Handle("/", gohtx.Handler(indexHndlr))
 
 	// Gohtx embedded assets handler
 	gohtx.AddGohtxAssetHandler()
 
 	// The update handler
-	http.Handle("/update", http.HandlerFunc(updateHndlr))
+	http.Handle("/update", gohtx.Handler(updateHndlr))
 
 	// The input handlers
-	http.Handle("/input", http.HandlerFunc(inputHndlr))
-	http.Handle("/unwrapped", http.HandlerFunc(unwrappedInputHndlr))
+	http.Handle("/input", gohtx.Handler(inputHndlr))
+	http.Handle("/unwrapped", gohtx.Handler(unwrappedInputHndlr))
 
 	// Fragment request handler
-	http.Handle("/fragment", http.HandlerFunc(fragmentHndlr))
+	http.Handle("/fragment", gohtx.Handler(fragmentHndlr))
 
 	// Static file request handler
 	http.Handle("/static/", http.FileServer(http.Dir("static")))
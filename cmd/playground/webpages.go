@@ -3,29 +3,19 @@ package main
 import (
 	"bytes"
 	"fmt"
-	"log"
 	"net/http"
 	"os"
 
 	. "github.com/Michael-F-Ellis/gohtx" // dot import makes sense here
+	"github.com/Michael-F-Ellis/gohtx/components/bulma"
 	"github.com/bitfield/script"
 )
 
 // indexHndlr generates and returns the index page.
-func indexHndlr(w http.ResponseWriter, r *http.Request) {
-	var buf bytes.Buffer
+func indexHndlr(r *http.Request) (*HtmlTree, error) {
 	// For this skeleton, we start a new session
 	// when the index page is loaded or reloaded.
-	err := Render(indexPage(newSession()), &buf, 0)
-	if err != nil {
-		log.Printf("%v", err)
-		w.WriteHeader(http.StatusInternalServerError)
-	} else {
-		_, err = w.Write(buf.Bytes())
-		if err != nil {
-			log.Println(err)
-		}
-	}
+	return indexPage(newSession()), nil
 }
 
 // indexPage creates the index page as a gohtx HTMLTree. The created
@@ -34,7 +24,7 @@ func indexPage(key string) (page *HtmlTree) {
 	// We use the Null pseudo-tag here to place the doctype
 	// outside the content of the html tag.
 	page = Null(
-		"<!DOCTYPE html>",
+		Raw("<!DOCTYPE html>"),
 		Html(``,
 			Head(``,
 				CustomHeadContent(true, true, true),
@@ -78,7 +68,7 @@ func indexBody(key string) (body *HtmlTree) {
 			// Title and subtitle
 			H1(`class="title has-text-centered"`, "Gohtx Playground"),
 			P(`class="subtitle is-info has-text-centered"`,
-				`with <b>HTMX</b>, <b>HyperScript</b> and <b>Bulma</b> CSS`),
+				Raw(`with <b>HTMX</b>, <b>HyperScript</b> and <b>Bulma</b> CSS`)),
 			// A dropdown select of example code fragments
 			Div(`class=container`,
 				mkSelect(
@@ -144,18 +134,18 @@ func formsAndResultsContent(src, result string, isHtml, isOk bool, nullwrap bool
 		Div(`id="pgsource" class="block"`,
 			// A form with textarea for code and a button to submit it.
 			Form(`id="pgsrcform" class="form" hx-post="/input" hx-target="#forms-and-results" hx-vals='{"lang":"go"}'`,
-				labeledFormField("Go Code", Textarea(`id="gocode" class=textarea name=gocode`, pgSourceContent)),
-				unlabeledFormField(Button(`class="button is-primary" type="submit"`, "Evaluate")),
+				bulma.Field(``, "Go Code", "gocode", nil, bulma.Control(``, nil, nil, bulma.Textarea(``, "gocode", pgSourceContent))),
+				bulma.Field(``, "", "", nil, bulma.Control(``, nil, nil, bulma.Button(`type="submit"`, bulma.Primary, "Evaluate"))),
 			),
 		),
 		// where the server response goes
-		Div(`id="pgtarget" class="block"`, pgTargetContent),
+		Div(`id="pgtarget" class="block"`, Raw(pgTargetContent)),
 
 		// Html code
 		Div(`id="pghtml" class="block"`,
 			Form(`class="form" hx-post="/input" hx-target="#forms-and-results" hx-vals='{"lang":"html"}'`,
-				labeledFormField("HTML", Textarea(`id="htmlcode" class=textarea name=htmlcode`, pgHtmlContent)),
-				unlabeledFormField(Button(`class="button is-primary" type="submit"`, "Gohtify")),
+				bulma.Field(``, "HTML", "htmlcode", nil, bulma.Control(``, nil, nil, bulma.Textarea(``, "htmlcode", pgHtmlContent))),
+				bulma.Field(``, "", "", nil, bulma.Control(``, nil, nil, bulma.Button(`type="submit"`, bulma.Primary, "Gohtify"))),
 			),
 		),
 	)
@@ -193,63 +183,34 @@ func resourceLinks() (div *HtmlTree) {
 	return
 }
 
-// labeledFormField wraps a label and control into a bulma form field.
-func labeledFormField(label string, control *HtmlTree) (field *HtmlTree) {
-	field = Div(`class="field"`,
-		Label(`class="label"`, label),
-		Div(`class="control"`, control))
-	return
-}
-
-// unlabeledFormField wraps a control into a bulma form field.
-func unlabeledFormField(control *HtmlTree) (field *HtmlTree) {
-	field = Div(`class="field"`,
-		Div(`class="control"`, control))
-	return
-}
-
 func mkSelect(optionNames []string, url, param, target string) (sel *HtmlTree) {
 	var options []interface{}
 	for _, name := range optionNames {
 		attrs := fmt.Sprintf(`value="%s"`, name)
 		options = append(options, Option(attrs, name))
 	}
-	attrs := fmt.Sprintf(`name="%s" hx-get="%s" hx-target="%s"`, param, url, target)
-	sel = labeledFormField("Examples", Div(`class="select is-link"`,
-		Select(attrs, options...)))
+	attrs := fmt.Sprintf(`hx-get="%s" hx-target="%s"`, url, target)
+	sel = bulma.Field(``, "Examples", param, nil,
+		bulma.Control(``, nil, nil, bulma.Select(attrs, param, append(options, bulma.Link)...)))
 	return
 }
 
 // updateHndlr responds to an update request. It verifies that
 // the request contains a valid session key before generating
-// and rendering the html.
-func updateHndlr(w http.ResponseWriter, r *http.Request) {
-	var buf bytes.Buffer
+// the html.
+func updateHndlr(r *http.Request) (*HtmlTree, error) {
 	key := r.URL.Query().Get("key")
 	if key == "" {
-		log.Println("No key in request")
-		w.WriteHeader(http.StatusBadRequest)
-		return
+		return nil, Errorf(http.StatusBadRequest, "no key in request")
 	}
 	count, ok := Sessions[key]
 	if !ok {
-		log.Printf("Invalid key in request: %s", key)
-		w.WriteHeader(http.StatusBadRequest)
-		return
+		return nil, Errorf(http.StatusBadRequest, "invalid key in request: %s", key)
 	}
 	count++
 	Sessions[key] = count
 
-	err := Render(updateResponse(count), &buf, 0)
-	if err != nil {
-		log.Printf("%v", err)
-		w.WriteHeader(http.StatusInternalServerError)
-	} else {
-		_, err = w.Write(buf.Bytes())
-		if err != nil {
-			log.Println(err)
-		}
-	}
+	return updateResponse(count), nil
 }
 
 // updateResponse response returns an html fragment containing a
@@ -286,12 +247,10 @@ func updaterButton() (div *HtmlTree) {
 // inputHndlr gets the user's Go code from the input textarea and tries to evaluate
 // it. It uses the eval function which puts the result of the evaluation into the supplied
 // buffer.
-func inputHndlr(w http.ResponseWriter, r *http.Request) {
+func inputHndlr(r *http.Request) (*HtmlTree, error) {
 	err := r.ParseForm()
 	if err != nil {
-		log.Printf("%v", err)
-		w.WriteHeader(http.StatusBadRequest)
-		return
+		return nil, Errorf(http.StatusBadRequest, "%v", err)
 	}
 	lang := r.FormValue("lang")
 	var (
@@ -309,46 +268,37 @@ func inputHndlr(w http.ResponseWriter, r *http.Request) {
 		ignore := map[string]struct{}{"html": {}, "head": {}, "body": {}}
 		err := Gohtify(code, true, ignore, &result)
 		if err != nil {
-			log.Printf("%v", err)
-			w.WriteHeader(http.StatusBadRequest)
-			return
+			return nil, Errorf(http.StatusBadRequest, "%v", err)
 		}
 		result = formsAndResultsContent(code, result, false, true, true)
 	default:
-		log.Printf("unknown lang:'%v'", lang)
-		w.WriteHeader(http.StatusBadRequest)
-		return
+		return nil, Errorf(http.StatusBadRequest, "unknown lang:'%v'", lang)
 	}
 
-	_, _ = w.Write([]byte(result))
+	return Null(Raw(result)), nil
 }
 
 // unwrappedInputHndlr gets the user's Go code from the input textarea and tries to evaluate
 // it. It uses the eval function which puts the result of the evaluation into the supplied
 // buffer.
-func unwrappedInputHndlr(w http.ResponseWriter, r *http.Request) {
+func unwrappedInputHndlr(r *http.Request) (*HtmlTree, error) {
 	err := r.ParseForm()
 	if err != nil {
-		log.Printf("%v", err)
-		w.WriteHeader(http.StatusBadRequest)
-		return
+		return nil, Errorf(http.StatusBadRequest, "%v", err)
 	}
 	code := r.FormValue("code")
 	htm, _ := eval(code, false) // don't insert the user's code into the template.
-	_, _ = w.Write([]byte(htm))
+	return Null(Raw(htm)), nil
 }
 
 // fragmentRequestHndlr returns the request code fragment
-func fragmentHndlr(w http.ResponseWriter, r *http.Request) {
+func fragmentHndlr(r *http.Request) (*HtmlTree, error) {
 	which := r.URL.Query().Get("which")
-	log.Println(which)
 	code, ok := Fragments[which]
 	if !ok {
-		w.WriteHeader(http.StatusNotFound)
-		return
+		return nil, Errorf(http.StatusNotFound, "no fragment named %q", which)
 	}
-	log.Println(code)
-	_, _ = w.Write([]byte(code))
+	return Null(Raw(code)), nil
 }
 
 // eval is called to evaluate Go code entered in the playground.
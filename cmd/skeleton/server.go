@@ -1,7 +1,6 @@
 package main
 
 import (
-	"bytes"
 	"fmt"
 	"log"
 	"math/rand"
@@ -25,15 +24,16 @@ func newSession() (key string) {
 
 // Serve serves web pages.
 func Serve() {
-	// Declare HandlerFuncs
+	// Declare handlers
 
-	// The index page handler
-	http.Handle("/", http.HandlerFunc(indexHndlr))
+	// The index page handler. gohtx.Handler renders the page before sending
+	// it, so a page that fails to render gets a 500 instead of a partial 200.
+	http.Handle("/", gohtx.Handler(indexHndlr))
 	// Embedded assets handler
 	// http.Handle("/gohtx/", http.HandlerFunc(gohtxAssetHndlr))
 	gohtx.AddGohtxAssetHandler()
 	// The update handler
-	http.Handle("/update", http.HandlerFunc(updateHndlr))
+	http.Handle("/update", gohtx.Handler(updateHndlr))
 
 	// Live installations for customers serve over HTTPS on port 443
 	// For testing we serve on localhost (default port 8080). The choice of
@@ -62,50 +62,34 @@ func Serve() {
 }
 
 // indexHndlr generates and returns the index page.
-func indexHndlr(w http.ResponseWriter, r *http.Request) {
-	var buf bytes.Buffer
+func indexHndlr(r *http.Request) (*gohtx.HtmlTree, error) {
 	// For this skeleton, we start a new session
 	// when the index page is loaded or reloaded.
-	err := gohtx.Render(indexPage(newSession()), &buf, 0)
-	if err != nil {
-		log.Printf("%v", err)
-		w.WriteHeader(http.StatusInternalServerError)
-	} else {
-		_, err = w.Write(buf.Bytes())
-		if err != nil {
-			log.Println(err)
-		}
-	}
+	return indexPage(newSession(), 0), nil
 }
 
 // updateHndlr responds to an update request. It verifies that
 // the request contains a valid session key before generating
-// and rendering the html.
-func updateHndlr(w http.ResponseWriter, r *http.Request) {
-	var buf bytes.Buffer
+// the html.
+func updateHndlr(r *http.Request) (*gohtx.HtmlTree, error) {
 	key := r.URL.Query().Get("key")
 	if key == "" {
-		log.Println("No key in request")
-		w.WriteHeader(http.StatusBadRequest)
-		return
+		return nil, gohtx.Errorf(http.StatusBadRequest, "no key in request")
 	}
 	count, ok := Sessions[key]
 	if !ok {
-		log.Printf("Invalid key in request: %s", key)
-		w.WriteHeader(http.StatusBadRequest)
-		return
+		return nil, gohtx.Errorf(http.StatusBadRequest, "invalid key in request: %s", key)
 	}
 	count++
 	Sessions[key] = count
 
-	err := gohtx.Render(updateResponse(count), &buf, 0)
-	if err != nil {
-		log.Printf("%v", err)
-		w.WriteHeader(http.StatusInternalServerError)
-	} else {
-		_, err = w.Write(buf.Bytes())
-		if err != nil {
-			log.Println(err)
-		}
+	// Requests from htmx get the fragment for the target, plus the counter
+	// swapped in out-of-band. Anything else, e.g. a reload after the
+	// browser's address bar was changed, gets the full page.
+	if !gohtx.ParseHxRequest(r).WantsFragment() {
+		return indexPage(key, count), nil
 	}
+	return gohtx.NewOOBResponse(updateResponse(count)).
+		OOB(updateCounter(count), gohtx.SwapOuterHTML, "").
+		Tree()
 }
@@ -4,39 +4,42 @@ import (
 	"fmt"
 
 	. "github.com/Michael-F-Ellis/gohtx" // dot import makes sense here
+	"github.com/Michael-F-Ellis/gohtx/components/bulma"
 )
 
 // indexPage creates the index page as a gohtx HTMLTree. The created
-// page will contain key as an hx-val to be used as a session key.
-func indexPage(key string) (page *HtmlTree) {
+// page will contain key as an hx-val to be used as a session key and
+// shows the number of updates so far.
+func indexPage(key string, updates uint64) (page *HtmlTree) {
 	// We use the Null pseudo-tag here to place the doctype
 	// outside the content of the html tag.
 	page = Null(
-		"<!DOCTYPE html>",
+		Raw("<!DOCTYPE html>"),
 		Html(``,
 			Head(``,
 				CustomHeadContent(true, true, true),
 				Title(``, `Skeleton App`),
 			),
-			indexBody(key),
+			indexBody(key, updates),
 		),
 	)
 	return
 }
 
 // indexBody returns the body element of the index.html page
-func indexBody(key string) (body *HtmlTree) {
-	sectionAttrs := fmt.Sprintf(`class=section hx-vals='{"key": "%s"}'`, key)
+func indexBody(key string, updates uint64) (body *HtmlTree) {
+	target := initialContent()
+	if updates > 0 {
+		target = updateResponse(updates)
+	}
 	body = Body(``,
-		Section(sectionAttrs,
+		Section(`class=section`, Hx.Vals(map[string]string{"key": key}),
 			H1(`class="title has-text-centered"`, "Gohtx App Skeleton"),
 			P(`class="subtitle is-info has-text-centered"`,
-				`with <b>HTMX</b>, <b>HyperScript</b> and <b>Bulma</b> CSS`),
+				Raw(`with <b>HTMX</b>, <b>HyperScript</b> and <b>Bulma</b> CSS`)),
+			P(`class="has-text-centered block"`, "Updates: ", updateCounter(updates)),
 
-			Div(`id="target" class="block"`,
-				Div(`class="block"`, "I've never been updated!"),
-				updaterButton(),
-			),
+			Div(`id="target" class="block"`, target),
 
 			Div(`class="block"`,
 				P(``, `Learn more about HTMX, HyperScript, and Bulma at their websites:`),
@@ -64,14 +67,31 @@ func indexBody(key string) (body *HtmlTree) {
 // has HyperScript that toggles the text color of the page title on each click.
 func updaterButton() (div *HtmlTree) {
 	div = Div(`class="block"`,
-		Button(`class="button is-primary is-medium" 
-		hx-get="/update" hx-target="#target"
-		script="on click toggle .has-text-primary on .title"
-		`, "Click Me!"),
+		bulma.Button(``, bulma.Primary, bulma.Medium,
+			Hx.Get("/update").Target("#target").
+				Script("on click toggle .has-text-primary on .title"),
+			"Click Me!"),
 	)
 	return
 }
 
+// initialContent returns the content of the update target before any
+// updates.
+func initialContent() (content *HtmlTree) {
+	content = Null(
+		Div(`class="block"`, "I've never been updated!"),
+		updaterButton(),
+	)
+	return
+}
+
+// updateCounter returns a tag showing the number of updates. Update responses
+// swap it in out-of-band, by id, since it's outside the update target.
+func updateCounter(updates uint64) (span *HtmlTree) {
+	span = bulma.Tag(`id="counter"`, bulma.Info, fmt.Sprint(updates))
+	return
+}
+
 // updateResponse response returns an html fragment containing a
 // message string about the number of updates in the current session
 // and an updater button.
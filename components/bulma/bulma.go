@@ -0,0 +1,182 @@
+// Package bulma builds Bulma components as gohtx trees, so you don't have to
+// remember the class names and markup Bulma expects.
+//
+// Every builder takes an attribute string, like the gohtx tag functions, and
+// content. The attribute string, and any Attributers in the content, apply to
+// the outer element of the component. The rest of the content goes where
+// Bulma expects it, e.g. in the body of a Message. Modifiers, like Primary,
+// Large and Active, are Attributers that add their class, so they can be
+// passed as content, e.g.
+//
+//	bulma.Button(``, bulma.Primary, bulma.Medium, gohtx.Hx.Get("/update"), "Click Me!")
+//
+// Modifiers work with the gohtx tag functions too, e.g.
+// gohtx.Div(`class=box`, bulma.Info).
+//
+// Bulma has no JavaScript. Components that open, close or toggle, like
+// Navbar's burger, Modal, Dropdown and deletable Notifications, do so with
+// HyperScript, so load it with the page, e.g. with
+// gohtx.HeadOptions{Hyperscript: true}.
+package bulma
+
+import (
+	"github.com/Michael-F-Ellis/gohtx"
+)
+
+// Color is a Bulma color modifier.
+type Color string
+
+const (
+	White   Color = "is-white"
+	Black   Color = "is-black"
+	Light   Color = "is-light"
+	Dark    Color = "is-dark"
+	Primary Color = "is-primary"
+	Link    Color = "is-link"
+	Info    Color = "is-info"
+	Success Color = "is-success"
+	Warning Color = "is-warning"
+	Danger  Color = "is-danger"
+)
+
+// Size is a Bulma size modifier.
+type Size string
+
+const (
+	Small  Size = "is-small"
+	Normal Size = "is-normal"
+	Medium Size = "is-medium"
+	Large  Size = "is-large"
+)
+
+// State is a Bulma state modifier.
+type State string
+
+const (
+	Hovered  State = "is-hovered"
+	Focused  State = "is-focused"
+	Active   State = "is-active"
+	Loading  State = "is-loading"
+	Static   State = "is-static"
+	Selected State = "is-selected"
+)
+
+// Modifier is any other Bulma modifier.
+type Modifier string
+
+const (
+	Outlined      Modifier = "is-outlined"
+	Inverted      Modifier = "is-inverted"
+	Rounded       Modifier = "is-rounded"
+	Fullwidth     Modifier = "is-fullwidth"
+	Centered      Modifier = "is-centered"
+	Right         Modifier = "is-right"
+	Up            Modifier = "is-up"
+	Hoverable     Modifier = "is-hoverable"
+	Boxed         Modifier = "is-boxed"
+	Toggle        Modifier = "is-toggle"
+	ToggleRounded Modifier = "is-toggle-rounded"
+	Fullheight    Modifier = "is-fullheight"
+	Halfheight    Modifier = "is-halfheight"
+	FixedTop      Modifier = "is-fixed-top"
+	FixedBottom   Modifier = "is-fixed-bottom"
+	Transparent   Modifier = "is-transparent"
+	Spaced        Modifier = "is-spaced"
+	HasAddons     Modifier = "has-addons"
+	HasShadow     Modifier = "has-shadow"
+	Grouped       Modifier = "is-grouped"
+	Multiline     Modifier = "is-grouped-multiline"
+	Expanded      Modifier = "is-expanded"
+	Multiple      Modifier = "is-multiple"
+)
+
+// Attributes implements gohtx.Attributer.
+func (c Color) Attributes() ([]gohtx.Attribute, error) { return classAttribute(string(c)) }
+
+// Attributes implements gohtx.Attributer.
+func (s Size) Attributes() ([]gohtx.Attribute, error) { return classAttribute(string(s)) }
+
+// Attributes implements gohtx.Attributer.
+func (s State) Attributes() ([]gohtx.Attribute, error) { return classAttribute(string(s)) }
+
+// Attributes implements gohtx.Attributer.
+func (m Modifier) Attributes() ([]gohtx.Attribute, error) { return classAttribute(string(m)) }
+
+// classAttribute returns a class attribute for name, or none if name is
+// empty, so a zero modifier adds nothing.
+func classAttribute(name string) ([]gohtx.Attribute, error) {
+	if name == "" {
+		return nil, nil
+	}
+	return []gohtx.Attribute{gohtx.Class(name)}, nil
+}
+
+// tagFunc is the signature of the gohtx functions for tags with content.
+type tagFunc func(a string, c ...interface{}) *gohtx.HtmlTree
+
+// hr is gohtx.Hr as a tagFunc. An <hr> is empty, so Render reports anything
+// in c other than Attributers.
+func hr(a string, c ...interface{}) *gohtx.HtmlTree {
+	line := gohtx.Hr(a)
+	line.C = append(line.C, c...)
+	return line
+}
+
+// element returns an element made by tag with the given classes, attributes
+// and content. Components pass default attributes, like aria-label, ahead of
+// a, so that the same attributes in a replace them.
+func element(tag tagFunc, classes, a string, content ...interface{}) *gohtx.HtmlTree {
+	if classes == "" {
+		return tag(a, content...)
+	}
+	return tag(a, append([]interface{}{gohtx.Class(classes)}, content...)...)
+}
+
+// splitContent separates the Attributers in content, which apply to the outer
+// element of a component, from the rest.
+func splitContent(content []interface{}) (attrs, rest []interface{}) {
+	for _, c := range content {
+		if _, ok := c.(gohtx.Attributer); ok {
+			attrs = append(attrs, c)
+		} else {
+			rest = append(rest, c)
+		}
+	}
+	return
+}
+
+// link returns an element made by tag, or an <a> with href if href isn't
+// empty, with the given classes, attributes and content. Active adds
+// is-active.
+func link(tag tagFunc, href string, active bool, classes, a string, content ...interface{}) *gohtx.HtmlTree {
+	if active {
+		classes += " " + string(Active)
+	}
+	if href == "" {
+		return element(tag, classes, a, content...)
+	}
+	return element(gohtx.A, classes, a, append([]interface{}{gohtx.Attr("href", href)}, content...)...)
+}
+
+// withClass returns a copy of item with classes added if it's a tree, or
+// item wrapped in an element made by wrap otherwise.
+func withClass(item interface{}, classes string, wrap tagFunc) *gohtx.HtmlTree {
+	if t, ok := item.(*gohtx.HtmlTree); ok {
+		copied := *t
+		copied.C = append([]interface{}{gohtx.Class(classes)}, t.C...)
+		return &copied
+	}
+	return element(wrap, classes, ``, item)
+}
+
+// hyperscript returns the _ attribute with HyperScript code.
+func hyperscript(code string) gohtx.Attribute {
+	return gohtx.Attr("_", code)
+}
+
+// Delete returns the small cross Bulma uses to close notifications, messages,
+// tags and modals. Add a HyperScript or htmx attribute to make it do
+// something.
+func Delete(a string, content ...interface{}) *gohtx.HtmlTree {
+	return element(gohtx.Button, "delete", `aria-label="close" `+a, content...)
+}
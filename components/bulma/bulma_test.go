@@ -0,0 +1,60 @@
+package bulma
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Michael-F-Ellis/gohtx"
+)
+
+// render checks the attributes, classes and content model of h and renders it
+// on one line.
+func render(t *testing.T, h *gohtx.HtmlTree) string {
+	t.Helper()
+	var perrs []gohtx.AttributeErrors
+	h.CheckAttributes(&perrs)
+	h.CheckClasses(&perrs)
+	for _, e := range perrs {
+		t.Errorf("%s %s: %v", e.Tag, e.Attrs, e.Errs)
+	}
+	var cerrs []gohtx.ContentModelError
+	h.CheckContentModel(&cerrs)
+	for _, e := range cerrs {
+		t.Errorf("content model: %v", e)
+	}
+	var buf bytes.Buffer
+	if err := gohtx.Render(h, &buf, -1); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestModifiers(t *testing.T) {
+	for _, test := range []struct {
+		h   *gohtx.HtmlTree
+		exp string
+	}{
+		{gohtx.Div(`class="box"`, Info, Large, Loading, Rounded), `<div class="box is-info is-large is-loading is-rounded"></div>`},
+		{gohtx.Div(``, Color("")), `<div></div>`},
+		{Button(``, Primary, Medium, "Go"), `<button class="button is-primary is-medium">Go</button>`},
+		// a's classes come first, and its attributes replace the defaults
+		{Delete(`class="is-small" aria-label="remove"`), `<button aria-label="remove" class="is-small delete"></button>`},
+	} {
+		if got := render(t, test.h); got != test.exp {
+			t.Errorf("expected %s, got %s", test.exp, got)
+		}
+	}
+}
+
+func TestWithClass(t *testing.T) {
+	item := gohtx.A(`href="/x"`, "X")
+	if got := render(t, withClass(item, "card-footer-item", gohtx.P)); got != `<a href="/x" class="card-footer-item">X</a>` {
+		t.Errorf("unexpected item %s", got)
+	}
+	if got := render(t, item); got != `<a href="/x">X</a>` {
+		t.Errorf("withClass modified its argument: %s", got)
+	}
+	if got := render(t, withClass("text", "card-footer-item", gohtx.P)); got != `<p class="card-footer-item">text</p>` {
+		t.Errorf("unexpected item %s", got)
+	}
+}
@@ -0,0 +1,304 @@
+package bulma
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Michael-F-Ellis/gohtx"
+)
+
+// Breadcrumb returns a breadcrumb trail of Crumbs.
+func Breadcrumb(a string, crumbs ...interface{}) *gohtx.HtmlTree {
+	attrs, rest := splitContent(crumbs)
+	return element(gohtx.Nav, "breadcrumb", `aria-label="breadcrumbs" `+a,
+		append(attrs, gohtx.Ul(``, rest...))...)
+}
+
+// Crumb returns an item of a Breadcrumb linking to href. Mark the current
+// page active.
+func Crumb(a, href string, active bool, content ...interface{}) *gohtx.HtmlTree {
+	attrs, rest := splitContent(content)
+	linkAttrs := []interface{}{gohtx.Attr("href", href)}
+	if active {
+		attrs = append(attrs, Active)
+		linkAttrs = append(linkAttrs, gohtx.Attr("aria-current", "page"))
+	}
+	return gohtx.Li(a, append(attrs, gohtx.A(``, append(linkAttrs, rest...)...))...)
+}
+
+// Card returns a card. Header, if not nil, is the title of the card's header
+// and image, if not nil, is shown above the content. Each footer item, a
+// link or button or anything else, becomes a card-footer-item.
+func Card(a string, header, image interface{}, footer []interface{}, content ...interface{}) *gohtx.HtmlTree {
+	attrs, rest := splitContent(content)
+	parts := attrs
+	if header != nil {
+		parts = append(parts, gohtx.Header(`class="card-header"`,
+			gohtx.P(`class="card-header-title"`, header)))
+	}
+	if image != nil {
+		parts = append(parts, gohtx.Div(`class="card-image"`, gohtx.Figure(`class="image"`, image)))
+	}
+	parts = append(parts, gohtx.Div(`class="card-content"`, rest...))
+	if len(footer) > 0 {
+		items := make([]interface{}, len(footer))
+		for i, item := range footer {
+			items[i] = withClass(item, "card-footer-item", gohtx.P)
+		}
+		parts = append(parts, gohtx.Footer(`class="card-footer"`, items...))
+	}
+	return element(gohtx.Div, "card", a, parts...)
+}
+
+// Dropdown returns a dropdown menu of DropdownItems and DropdownDividers,
+// opened and closed by a button showing label. The menu gets id, which the
+// button refers to for accessibility. Add Hoverable to open it on hover
+// instead, without HyperScript.
+func Dropdown(a, id string, label interface{}, items ...interface{}) *gohtx.HtmlTree {
+	attrs, rest := splitContent(items)
+	trigger := gohtx.Div(`class="dropdown-trigger"`,
+		Button(``,
+			gohtx.Attrs{"aria-haspopup": "true", "aria-controls": id},
+			hyperscript("on click toggle .is-active on closest .dropdown"),
+			gohtx.Span(``, label)))
+	menu := gohtx.Div(`class="dropdown-menu" role="menu"`, gohtx.Attr("id", id),
+		gohtx.Div(`class="dropdown-content"`, rest...))
+	return element(gohtx.Div, "dropdown", a, append(attrs, trigger, menu)...)
+}
+
+// DropdownItem returns an item of a Dropdown, a link if href isn't empty.
+func DropdownItem(a, href string, active bool, content ...interface{}) *gohtx.HtmlTree {
+	return link(gohtx.Div, href, active, "dropdown-item", a, content...)
+}
+
+// DropdownDivider returns a line between DropdownItems. The content may only
+// hold Attributers, since a line has no content of its own.
+func DropdownDivider(a string, content ...interface{}) *gohtx.HtmlTree {
+	return element(hr, "dropdown-divider", a, content...)
+}
+
+// Menu returns a vertical menu of MenuLabels and MenuLists.
+func Menu(a string, content ...interface{}) *gohtx.HtmlTree {
+	return element(gohtx.Aside, "menu", a, content...)
+}
+
+// MenuLabel returns the label of a section of a Menu.
+func MenuLabel(a string, content ...interface{}) *gohtx.HtmlTree {
+	return element(gohtx.P, "menu-label", a, content...)
+}
+
+// MenuList returns a list of MenuItems.
+func MenuList(a string, items ...interface{}) *gohtx.HtmlTree {
+	return element(gohtx.Ul, "menu-list", a, items...)
+}
+
+// MenuItem returns an item of a MenuList linking to href with label. The
+// content, e.g. a nested MenuList, follows the link.
+func MenuItem(a, href string, active bool, label interface{}, content ...interface{}) *gohtx.HtmlTree {
+	attrs, rest := splitContent(content)
+	return gohtx.Li(a, append(append(attrs, link(gohtx.A, href, active, "", ``, label)), rest...)...)
+}
+
+// Message returns a message. Header, if not nil, is the title of its header.
+// If deletable, the header has a Delete button that removes the message.
+func Message(a string, header interface{}, deletable bool, content ...interface{}) *gohtx.HtmlTree {
+	attrs, rest := splitContent(content)
+	parts := attrs
+	if header != nil || deletable {
+		var title []interface{}
+		if header != nil {
+			title = append(title, gohtx.P(``, header))
+		}
+		if deletable {
+			title = append(title, Delete(``, hyperscript("on click remove closest .message")))
+		}
+		parts = append(parts, gohtx.Div(`class="message-header"`, title...))
+	}
+	parts = append(parts, gohtx.Div(`class="message-body"`, rest...))
+	return element(gohtx.Article, "message", a, parts...)
+}
+
+// Modal returns a modal showing content. It's hidden until opened by an
+// element with OpenModal(id), or rendered with Active. Clicking the
+// background or the close button hides it again.
+func Modal(a, id string, content ...interface{}) *gohtx.HtmlTree {
+	attrs, rest := splitContent(content)
+	return element(gohtx.Div, "modal", a, append(attrs,
+		gohtx.Attr("id", id),
+		gohtx.Div(`class="modal-background"`, CloseModal()),
+		gohtx.Div(`class="modal-content"`, rest...),
+		gohtx.Button(`class="modal-close is-large" aria-label="close"`, CloseModal()),
+	)...)
+}
+
+// ModalCard is like Modal but shows content in a card with title, a Delete
+// button that closes it, and footer, e.g. buttons to confirm or cancel.
+func ModalCard(a, id string, title interface{}, footer []interface{}, content ...interface{}) *gohtx.HtmlTree {
+	attrs, rest := splitContent(content)
+	card := []interface{}{
+		gohtx.Header(`class="modal-card-head"`,
+			gohtx.P(`class="modal-card-title"`, title),
+			Delete(``, CloseModal())),
+		gohtx.Section(`class="modal-card-body"`, rest...),
+	}
+	if len(footer) > 0 {
+		card = append(card, gohtx.Footer(`class="modal-card-foot"`, footer...))
+	}
+	return element(gohtx.Div, "modal", a, append(attrs,
+		gohtx.Attr("id", id),
+		gohtx.Div(`class="modal-background"`, CloseModal()),
+		gohtx.Div(`class="modal-card"`, card...),
+	)...)
+}
+
+// OpenModal returns a HyperScript attribute that opens the Modal with id when
+// its element is clicked, e.g.
+//
+//	Button(``, OpenModal("confirm"), "Delete")
+func OpenModal(id string) gohtx.Attribute {
+	return hyperscript("on click add .is-active to #" + id)
+}
+
+// CloseModal returns a HyperScript attribute that closes the enclosing Modal
+// when its element is clicked, e.g. for a cancel button.
+func CloseModal() gohtx.Attribute {
+	return hyperscript("on click remove .is-active from closest .modal")
+}
+
+// Navbar returns a navigation bar with brand items on the left, always
+// shown, then start and end items in a menu with id. On narrow screens the
+// menu collapses and a burger button toggles it.
+func Navbar(a, id string, brand, start, end []interface{}, content ...interface{}) *gohtx.HtmlTree {
+	attrs, rest := splitContent(content)
+	burger := gohtx.A(`class="navbar-burger" role="button" aria-label="menu" aria-expanded="false"`,
+		gohtx.Data("target", id),
+		hyperscript("on click toggle .is-active on me then toggle .is-active on #"+id),
+		gohtx.Span(`aria-hidden="true"`), gohtx.Span(`aria-hidden="true"`), gohtx.Span(`aria-hidden="true"`))
+	parts := append(attrs,
+		gohtx.Div(`class="navbar-brand"`, append(append([]interface{}{}, brand...), burger)...),
+		gohtx.Div(`class="navbar-menu"`, gohtx.Attr("id", id),
+			gohtx.Div(`class="navbar-start"`, start...),
+			gohtx.Div(`class="navbar-end"`, end...)))
+	return element(gohtx.Nav, "navbar", `role="navigation" aria-label="main navigation" `+a, append(parts, rest...)...)
+}
+
+// NavbarItem returns an item of a Navbar, a link if href isn't empty.
+func NavbarItem(a, href string, active bool, content ...interface{}) *gohtx.HtmlTree {
+	return link(gohtx.Div, href, active, "navbar-item", a, content...)
+}
+
+// NavbarDropdown returns an item of a Navbar that shows label and, on hover,
+// a dropdown of NavbarItems and NavbarDividers.
+func NavbarDropdown(a string, label interface{}, items ...interface{}) *gohtx.HtmlTree {
+	attrs, rest := splitContent(items)
+	return element(gohtx.Div, "navbar-item has-dropdown is-hoverable", a, append(attrs,
+		gohtx.A(`class="navbar-link"`, label),
+		gohtx.Div(`class="navbar-dropdown"`, rest...))...)
+}
+
+// NavbarDivider returns a line between the items of a NavbarDropdown. The
+// content may only hold Attributers, since a line has no content of its own.
+func NavbarDivider(a string, content ...interface{}) *gohtx.HtmlTree {
+	return element(hr, "navbar-divider", a, content...)
+}
+
+// Pagination returns links to the pages before and after current, and to
+// pages 1 to total, with ellipses for the ones skipped. href returns the URL
+// of a page. The content follows the list of pages.
+func Pagination(a string, current, total int, href func(page int) string, content ...interface{}) *gohtx.HtmlTree {
+	attrs, rest := splitContent(content)
+	step := func(class, label string, page int) *gohtx.HtmlTree {
+		if page < 1 || page > total {
+			return gohtx.A(``, gohtx.Class(class, "is-disabled"), label)
+		}
+		return gohtx.A(``, gohtx.Class(class), gohtx.Attr("href", href(page)), label)
+	}
+	var pages []interface{}
+	for _, page := range pageList(current, total) {
+		switch {
+		case page == 0:
+			pages = append(pages, gohtx.Li(``, gohtx.Span(`class="pagination-ellipsis"`, "…")))
+		case page == current:
+			pages = append(pages, gohtx.Li(``, gohtx.A(`class="pagination-link is-current" aria-current="page"`,
+				gohtx.Attrs{"href": href(page), "aria-label": fmt.Sprintf("Page %d", page)}, fmt.Sprint(page))))
+		default:
+			pages = append(pages, gohtx.Li(``, gohtx.A(`class="pagination-link"`,
+				gohtx.Attrs{"href": href(page), "aria-label": fmt.Sprintf("Go to page %d", page)}, fmt.Sprint(page))))
+		}
+	}
+	parts := append(attrs,
+		step("pagination-previous", "Previous", current-1),
+		step("pagination-next", "Next", current+1),
+		gohtx.Ul(`class="pagination-list"`, pages...))
+	return element(gohtx.Nav, "pagination", `role="navigation" aria-label="pagination" `+a, append(parts, rest...)...)
+}
+
+// pageList returns the pages Pagination links to: the first, the last, and
+// current with its neighbors, in order. Gaps of more than one page are marked
+// by a 0, for an ellipsis. A gap of one page is filled with the page, since
+// it takes no more room than the ellipsis.
+func pageList(current, total int) (pages []int) {
+	shown := make(map[int]bool)
+	for _, p := range []int{1, current - 1, current, current + 1, total} {
+		if p >= 1 && p <= total {
+			shown[p] = true
+		}
+	}
+	sorted := make([]int, 0, len(shown))
+	for p := range shown {
+		sorted = append(sorted, p)
+	}
+	sort.Ints(sorted)
+	last := 0
+	for _, p := range sorted {
+		switch p - last {
+		case 1:
+		case 2:
+			pages = append(pages, p-1)
+		default:
+			pages = append(pages, 0)
+		}
+		pages = append(pages, p)
+		last = p
+	}
+	return
+}
+
+// Panel returns a panel with heading, if not nil, followed by PanelTabs,
+// PanelBlocks or other content.
+func Panel(a string, heading interface{}, content ...interface{}) *gohtx.HtmlTree {
+	if heading != nil {
+		content = append([]interface{}{gohtx.P(`class="panel-heading"`, heading)}, content...)
+	}
+	return element(gohtx.Nav, "panel", a, content...)
+}
+
+// PanelTabs returns a row of links, with the active one marked Active, for
+// filtering the blocks of a Panel.
+func PanelTabs(a string, content ...interface{}) *gohtx.HtmlTree {
+	return element(gohtx.P, "panel-tabs", a, content...)
+}
+
+// PanelBlock returns a block of a Panel, a link if href isn't empty.
+func PanelBlock(a, href string, active bool, content ...interface{}) *gohtx.HtmlTree {
+	return link(gohtx.Div, href, active, "panel-block", a, content...)
+}
+
+// Tabs returns a row of TabItems.
+func Tabs(a string, items ...interface{}) *gohtx.HtmlTree {
+	attrs, rest := splitContent(items)
+	return element(gohtx.Div, "tabs", a, append(attrs, gohtx.Ul(``, rest...))...)
+}
+
+// TabItem returns a tab linking to href. Mark the current tab active.
+func TabItem(a, href string, active bool, content ...interface{}) *gohtx.HtmlTree {
+	attrs, rest := splitContent(content)
+	if active {
+		attrs = append(attrs, Active)
+	}
+	var linkAttrs []interface{}
+	if href != "" {
+		linkAttrs = append(linkAttrs, gohtx.Attr("href", href))
+	}
+	return gohtx.Li(a, append(attrs, gohtx.A(``, append(linkAttrs, rest...)...))...)
+}
@@ -0,0 +1,111 @@
+package bulma
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Michael-F-Ellis/gohtx"
+)
+
+func TestComponents(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		h    *gohtx.HtmlTree
+		exp  string
+	}{
+		{"breadcrumb", Breadcrumb(``, Centered, Crumb(``, "/", false, "Home"), Crumb(``, "/docs", true, "Docs")),
+			`<nav aria-label="breadcrumbs" class="breadcrumb is-centered"><ul><li><a href="/">Home</a></li>` +
+				`<li class="is-active"><a href="/docs" aria-current="page">Docs</a></li></ul></nav>`},
+		{"card", Card(``, "Title", gohtx.Img(`src="/a.png" alt="A"`), []interface{}{gohtx.A(`href="/edit"`, "Edit"), "Saved"}, Hoverable, "Body"),
+			`<div class="card is-hoverable"><header class="card-header"><p class="card-header-title">Title</p></header>` +
+				`<div class="card-image"><figure class="image"><img src="/a.png" alt="A"></figure></div>` +
+				`<div class="card-content">Body</div>` +
+				`<footer class="card-footer"><a href="/edit" class="card-footer-item">Edit</a><p class="card-footer-item">Saved</p></footer></div>`},
+		{"card without parts", Card(``, nil, nil, nil, "Body"), `<div class="card"><div class="card-content">Body</div></div>`},
+		{"dropdown", Dropdown(``, "actions", "Actions", Right, DropdownItem(``, "/a", true, "A"), DropdownDivider(`id="sep"`), DropdownItem(``, "", false, "B")),
+			`<div class="dropdown is-right"><div class="dropdown-trigger"><button class="button" aria-controls="actions" aria-haspopup="true" _="on click toggle .is-active on closest .dropdown"><span>Actions</span></button></div>` +
+				`<div class="dropdown-menu" role="menu" id="actions"><div class="dropdown-content">` +
+				`<a class="dropdown-item is-active" href="/a">A</a><hr id="sep" class="dropdown-divider"><div class="dropdown-item">B</div></div></div></div>`},
+		{"menu", Menu(``, MenuLabel(``, "General"), MenuList(``, MenuItem(``, "/", true, "Dashboard"),
+			MenuItem(``, "/team", false, "Team", MenuList(``, MenuItem(``, "/team/new", false, "New"))))),
+			`<aside class="menu"><p class="menu-label">General</p><ul class="menu-list"><li><a class="is-active" href="/">Dashboard</a></li>` +
+				`<li><a href="/team">Team</a><ul class="menu-list"><li><a href="/team/new">New</a></li></ul></li></ul></aside>`},
+		{"message", Message(``, "Oops", true, Danger, "Something broke"),
+			`<article class="message is-danger"><div class="message-header"><p>Oops</p><button aria-label="close" class="delete" _="on click remove closest .message"></button></div>` +
+				`<div class="message-body">Something broke</div></article>`},
+		{"message without header", Message(``, nil, false, "Hi"), `<article class="message"><div class="message-body">Hi</div></article>`},
+		{"modal", Modal(``, "pic", gohtx.P(``, "Hello")),
+			`<div class="modal" id="pic"><div class="modal-background" _="on click remove .is-active from closest .modal"></div>` +
+				`<div class="modal-content"><p>Hello</p></div>` +
+				`<button class="modal-close is-large" aria-label="close" _="on click remove .is-active from closest .modal"></button></div>`},
+		{"modal card", ModalCard(``, "confirm", "Sure?", []interface{}{Button(``, Danger, "Delete"), Button(``, CloseModal(), "Cancel")}, Active, "This can't be undone."),
+			`<div class="modal is-active" id="confirm"><div class="modal-background" _="on click remove .is-active from closest .modal"></div>` +
+				`<div class="modal-card"><header class="modal-card-head"><p class="modal-card-title">Sure?</p>` +
+				`<button aria-label="close" class="delete" _="on click remove .is-active from closest .modal"></button></header>` +
+				`<section class="modal-card-body">This can&#39;t be undone.</section>` +
+				`<footer class="modal-card-foot"><button class="button is-danger">Delete</button>` +
+				`<button class="button" _="on click remove .is-active from closest .modal">Cancel</button></footer></div></div>`},
+		{"opener", Button(``, OpenModal("confirm"), "Delete"), `<button class="button" _="on click add .is-active to #confirm">Delete</button>`},
+		{"navbar", Navbar(`aria-label="site"`, "main-menu",
+			[]interface{}{NavbarItem(``, "/", false, gohtx.Strong(``, "Gohtx"))},
+			[]interface{}{NavbarItem(``, "/docs", true, "Docs"), NavbarDropdown(``, "More", NavbarItem(``, "/about", false, "About"), NavbarDivider(``, gohtx.Attr("id", "more-sep")), NavbarItem(``, "/bugs", false, "Report a bug"))},
+			[]interface{}{NavbarItem(``, "", false, Buttons(``, ButtonLink(``, "/login", Light, "Log in")))},
+			Primary),
+			`<nav role="navigation" aria-label="site" class="navbar is-primary">` +
+				`<div class="navbar-brand"><a class="navbar-item" href="/"><strong>Gohtx</strong></a>` +
+				`<a class="navbar-burger" role="button" aria-label="menu" aria-expanded="false" data-target="main-menu" _="on click toggle .is-active on me then toggle .is-active on #main-menu">` +
+				`<span aria-hidden="true"></span><span aria-hidden="true"></span><span aria-hidden="true"></span></a></div>` +
+				`<div class="navbar-menu" id="main-menu"><div class="navbar-start"><a class="navbar-item is-active" href="/docs">Docs</a>` +
+				`<div class="navbar-item has-dropdown is-hoverable"><a class="navbar-link">More</a><div class="navbar-dropdown">` +
+				`<a class="navbar-item" href="/about">About</a><hr class="navbar-divider" id="more-sep"><a class="navbar-item" href="/bugs">Report a bug</a></div></div></div>` +
+				`<div class="navbar-end"><div class="navbar-item"><div class="buttons"><a class="button is-light" href="/login">Log in</a></div></div></div></div></nav>`},
+		{"panel", Panel(``, "Files", Info, PanelTabs(``, gohtx.A(``, Active, "All"), gohtx.A(`href="/src"`, "Source")),
+			PanelBlock(``, "/a.go", true, "a.go"), PanelBlock(``, "", false, Button(``, Fullwidth, "Reset"))),
+			`<nav class="panel is-info"><p class="panel-heading">Files</p><p class="panel-tabs"><a class="is-active">All</a><a href="/src">Source</a></p>` +
+				`<a class="panel-block is-active" href="/a.go">a.go</a><div class="panel-block"><button class="button is-fullwidth">Reset</button></div></nav>`},
+		{"tabs", Tabs(``, Boxed, Centered, TabItem(``, "#pics", true, "Pictures"), TabItem(``, "", false, gohtx.Hx.Get("/music"), "Music")),
+			`<div class="tabs is-boxed is-centered"><ul><li class="is-active"><a href="#pics">Pictures</a></li><li hx-get="/music"><a>Music</a></li></ul></div>`},
+	} {
+		if got := render(t, test.h); got != test.exp {
+			t.Errorf("%s: expected\n%s, got\n%s", test.name, test.exp, got)
+		}
+	}
+}
+
+func TestPagination(t *testing.T) {
+	href := func(page int) string { return fmt.Sprintf("/list?page=%d", page) }
+	got := render(t, Pagination(``, 1, 3, href, Small, Rounded))
+	for _, want := range []string{
+		`<nav role="navigation" aria-label="pagination" class="pagination is-small is-rounded">`,
+		`<a class="pagination-previous is-disabled">Previous</a>`,
+		`<a class="pagination-next" href="/list?page=2">Next</a>`,
+		`<a class="pagination-link is-current" aria-current="page" aria-label="Page 1" href="/list?page=1">1</a>`,
+		`<a class="pagination-link" aria-label="Go to page 3" href="/list?page=3">3</a>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %s in %s", want, got)
+		}
+	}
+	if got := render(t, Pagination(``, 9, 9, href)); !strings.Contains(got, `<a class="pagination-next is-disabled">Next</a>`) {
+		t.Errorf("expected a disabled next link on the last page, got %s", got)
+	}
+
+	for _, test := range []struct {
+		current, total int
+		exp            []int
+	}{
+		{1, 1, []int{1}},
+		{1, 3, []int{1, 2, 3}},
+		{5, 10, []int{1, 0, 4, 5, 6, 0, 10}},
+		{3, 10, []int{1, 2, 3, 4, 0, 10}},
+		{4, 10, []int{1, 2, 3, 4, 5, 0, 10}}, // a gap of one page shows the page
+		{10, 10, []int{1, 0, 9, 10}},
+		{1, 0, nil},
+	} {
+		if got := pageList(test.current, test.total); !reflect.DeepEqual(got, test.exp) {
+			t.Errorf("pageList(%d, %d): expected %v, got %v", test.current, test.total, test.exp, got)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package bulma
+
+import (
+	"github.com/Michael-F-Ellis/gohtx"
+)
+
+// Button returns a button, e.g.
+//
+//	Button(``, Primary, Medium, gohtx.Hx.Get("/update"), "Click Me!")
+func Button(a string, content ...interface{}) *gohtx.HtmlTree {
+	return element(gohtx.Button, "button", a, content...)
+}
+
+// ButtonLink returns a link to href styled as a button.
+func ButtonLink(a, href string, content ...interface{}) *gohtx.HtmlTree {
+	return link(gohtx.A, href, false, "button", a, content...)
+}
+
+// Buttons returns a group of buttons. Add HasAddons to join them.
+func Buttons(a string, content ...interface{}) *gohtx.HtmlTree {
+	return element(gohtx.Div, "buttons", a, content...)
+}
+
+// Notification returns a notification. If deletable, it has a Delete button
+// that removes it.
+func Notification(a string, deletable bool, content ...interface{}) *gohtx.HtmlTree {
+	if deletable {
+		content = append([]interface{}{
+			Delete(``, hyperscript("on click remove closest .notification")),
+		}, content...)
+	}
+	return element(gohtx.Div, "notification", a, content...)
+}
+
+// Tag returns a tag. Put a Delete in it, with Small, to make it deletable.
+func Tag(a string, content ...interface{}) *gohtx.HtmlTree {
+	return element(gohtx.Span, "tag", a, content...)
+}
+
+// Tags returns a list of tags. Add HasAddons to join them.
+func Tags(a string, tags ...interface{}) *gohtx.HtmlTree {
+	return element(gohtx.Div, "tags", a, tags...)
+}
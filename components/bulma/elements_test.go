@@ -0,0 +1,26 @@
+package bulma
+
+import (
+	"testing"
+
+	"github.com/Michael-F-Ellis/gohtx"
+)
+
+func TestElements(t *testing.T) {
+	for _, test := range []struct {
+		h   *gohtx.HtmlTree
+		exp string
+	}{
+		{Buttons(``, HasAddons, Button(``, Selected, "A"), ButtonLink(``, "/b", Outlined, "B")),
+			`<div class="buttons has-addons"><button class="button is-selected">A</button><a class="button is-outlined" href="/b">B</a></div>`},
+		{Notification(``, true, Warning, "Careful!"),
+			`<div class="notification is-warning"><button aria-label="close" class="delete" _="on click remove closest .notification"></button>Careful!</div>`},
+		{Notification(`id="n"`, false, "Saved"), `<div id="n" class="notification">Saved</div>`},
+		{Tags(``, HasAddons, Tag(``, Dark, "go"), Tag(``, Info, "1.16", Delete(``, Small))),
+			`<div class="tags has-addons"><span class="tag is-dark">go</span><span class="tag is-info">1.16<button aria-label="close" class="delete is-small"></button></span></div>`},
+	} {
+		if got := render(t, test.h); got != test.exp {
+			t.Errorf("expected\n%s, got\n%s", test.exp, got)
+		}
+	}
+}
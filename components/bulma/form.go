@@ -0,0 +1,228 @@
+package bulma
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/Michael-F-Ellis/gohtx"
+)
+
+// Field returns a form field with a label for the control with the given id,
+// followed by the content, usually one or more Controls. Label and id may be
+// empty. Add HasAddons or Grouped to lay out several controls on one line.
+//
+// Problem is an error, a message or nil. If it isn't empty, the field shows
+// it as help text below the controls, and marks the inputs, textareas,
+// selects and file inputs made by this package with Danger and
+// aria-invalid, e.g.
+//
+//	Field(``, "Email", "email", err,
+//		Control(``, "@", nil, Input(``, "email", "email")))
+func Field(a, label, id string, problem interface{}, content ...interface{}) *gohtx.HtmlTree {
+	attrs, rest := splitContent(content)
+	var body []interface{}
+	if label != "" {
+		body = append(body, element(gohtx.Label, "label", ``, append(idAttr("for", id), label)...))
+	}
+	if msg := problemText(problem); msg != "" {
+		var helpID []interface{}
+		if id != "" {
+			helpID = idAttr("id", id+"-help")
+		}
+		body = append(body, markInvalid(rest, id)...)
+		body = append(body, Help(``, append(helpID, Danger, msg)...))
+	} else {
+		body = append(body, rest...)
+	}
+	return element(gohtx.Div, "field", a, append(attrs, body...)...)
+}
+
+// Control returns a control holding a form input, with optional left and
+// right icons. Left and right are any content for the icon, e.g. an <i> from
+// an icon font, or nil for none.
+func Control(a string, left, right interface{}, content ...interface{}) *gohtx.HtmlTree {
+	classes := "control"
+	if left != nil {
+		classes += " has-icons-left"
+		content = append(content, element(gohtx.Span, "icon is-small is-left", ``, left))
+	}
+	if right != nil {
+		classes += " has-icons-right"
+		content = append(content, element(gohtx.Span, "icon is-small is-right", ``, right))
+	}
+	return element(gohtx.Div, classes, a, content...)
+}
+
+// Help returns help text for a field. Add a Color to change its color.
+func Help(a string, content ...interface{}) *gohtx.HtmlTree {
+	return element(gohtx.P, "help", a, content...)
+}
+
+// Input returns an input of type typ, e.g. "text" or "email", with the given
+// id. Its name is id too, unless a sets another.
+func Input(a, id, typ string, attrs ...gohtx.Attributer) *gohtx.HtmlTree {
+	defaults := attributers(append([]interface{}{gohtx.Class("input"), gohtx.Attr("type", typ)}, idAttr("id", id)...))
+	return gohtx.Input(named(id, a)).With(append(defaults, attrs...)...)
+}
+
+// Textarea returns a textarea with the given id and text. Its name is id too,
+// unless a sets another.
+func Textarea(a, id string, content ...interface{}) *gohtx.HtmlTree {
+	return element(gohtx.Textarea, "textarea", named(id, a), append(idAttr("id", id), content...)...)
+}
+
+// Select returns a select with the given id and options, in the wrapper Bulma
+// styles. Its name is id too, unless a sets another. Colors, sizes, states and
+// modifiers in the content apply to the wrapper, and other Attributers to the
+// select. For a multiple select, add Multiple and gohtx.BoolAttr("multiple").
+func Select(a, id string, content ...interface{}) *gohtx.HtmlTree {
+	modifiers, rest := splitModifiers(content)
+	sel := gohtx.Select(named(id, a), append(idAttr("id", id), rest...)...)
+	return element(gohtx.Div, "select", ``, append(modifiers, sel)...)
+}
+
+// Checkbox returns a checkbox with the given id in a label with the content.
+// Its name is id too, unless a sets another. Attributers in the content, like
+// gohtx.BoolAttr("checked"), apply to the checkbox.
+func Checkbox(a, id string, content ...interface{}) *gohtx.HtmlTree {
+	return choice("checkbox", named(id, a), idAttr("id", id), content)
+}
+
+// Radio returns a radio button with the given id, name and value in a label
+// with the content. Attributers in the content, like gohtx.BoolAttr("checked"),
+// apply to the radio button.
+func Radio(a, id, name, value string, content ...interface{}) *gohtx.HtmlTree {
+	attrs := append(idAttr("id", id), gohtx.Attr("name", name), gohtx.Attr("value", value))
+	return choice("radio", a, attrs, content)
+}
+
+// File returns a file input with the given id, and a call to action showing
+// label. Its name is id too, unless a sets another. Colors, sizes and
+// modifiers in the content apply to the wrapper, other Attributers, like
+// gohtx.Attr("accept", "image/*"), to the input, and the rest, like a
+// file-name span, follows the call to action.
+func File(a, id, label string, content ...interface{}) *gohtx.HtmlTree {
+	modifiers, rest := splitModifiers(content)
+	attrs, rest := splitContent(rest)
+	attrs = append(append([]interface{}{gohtx.Class("file-input"), gohtx.Attr("type", "file")}, idAttr("id", id)...), attrs...)
+	input := gohtx.Input(named(id, a)).With(attributers(attrs)...)
+	cta := gohtx.Span(`class="file-cta"`, gohtx.Span(`class="file-label"`, label))
+	return element(gohtx.Div, "file", ``,
+		append(modifiers, gohtx.Label(`class="file-label"`, append([]interface{}{input, cta}, rest...)...))...)
+}
+
+// choice returns a checkbox or radio button of the given type in a label with
+// the content.
+func choice(typ, a string, attrs, content []interface{}) *gohtx.HtmlTree {
+	more, rest := splitContent(content)
+	attrs = append(append([]interface{}{gohtx.Attr("type", typ)}, attrs...), more...)
+	input := gohtx.Input(a).With(attributers(attrs)...)
+	return element(gohtx.Label, typ, ``, append([]interface{}{input, " "}, rest...)...)
+}
+
+// dangerClasses are the classes of the controls Field marks with Danger.
+var dangerClasses = []string{"input", "textarea", "select", "file"}
+
+// markInvalid returns a copy of content with the controls made by this
+// package marked with Danger, and the inputs, selects and textareas marked
+// aria-invalid and described by the help for the field with the given id.
+func markInvalid(content []interface{}, id string) []interface{} {
+	marked := make([]interface{}, len(content))
+	for i, c := range content {
+		t, ok := c.(*gohtx.HtmlTree)
+		if !ok {
+			marked[i] = c
+			continue
+		}
+		copied := *t
+		copied.C = markInvalid(t.C, id)
+		if hasClass(t, dangerClasses...) {
+			copied.C = append(copied.C, Danger)
+		}
+		switch t.T {
+		case "input", "select", "textarea":
+			copied.C = append(copied.C, gohtx.Attr("aria-invalid", "true"))
+			if id != "" {
+				copied.C = append(copied.C, gohtx.Attr("aria-describedby", id+"-help"))
+			}
+		}
+		marked[i] = &copied
+	}
+	return marked
+}
+
+// hasClass reports whether the Class attributes in the content of t include
+// any of names.
+func hasClass(t *gohtx.HtmlTree, names ...string) bool {
+	for _, c := range t.C {
+		a, ok := c.(gohtx.Attribute)
+		if !ok || a.Name != "class" {
+			continue
+		}
+		for _, class := range strings.Fields(a.Value) {
+			for _, name := range names {
+				if class == name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// splitModifiers separates the Bulma modifiers in content from the rest.
+func splitModifiers(content []interface{}) (modifiers, rest []interface{}) {
+	for _, c := range content {
+		switch c.(type) {
+		case Color, Size, State, Modifier:
+			modifiers = append(modifiers, c)
+		default:
+			rest = append(rest, c)
+		}
+	}
+	return
+}
+
+// problemText returns the text of problem, which may be an error, a string or
+// nil.
+func problemText(problem interface{}) string {
+	switch p := problem.(type) {
+	case nil:
+		return ""
+	case error:
+		return p.Error()
+	case string:
+		return p
+	default:
+		return fmt.Sprint(p)
+	}
+}
+
+// named returns a with a name attribute for id ahead of it, so a can replace
+// it.
+func named(id, a string) string {
+	if id == "" {
+		return a
+	}
+	return `name="` + html.EscapeString(id) + `" ` + a
+}
+
+// idAttr returns content with the named attribute set to id, or no content if
+// id is empty.
+func idAttr(name, id string) []interface{} {
+	if id == "" {
+		return nil
+	}
+	return []interface{}{gohtx.Attr(name, id)}
+}
+
+// attributers returns the Attributers in content.
+func attributers(content []interface{}) (attrs []gohtx.Attributer) {
+	for _, c := range content {
+		if a, ok := c.(gohtx.Attributer); ok {
+			attrs = append(attrs, a)
+		}
+	}
+	return
+}
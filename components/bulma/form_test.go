@@ -0,0 +1,63 @@
+package bulma
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Michael-F-Ellis/gohtx"
+)
+
+func TestForm(t *testing.T) {
+	for _, test := range []struct {
+		h   *gohtx.HtmlTree
+		exp string
+	}{
+		{Field(``, "Name", "name", nil, Control(``, nil, nil, Input(`placeholder="Jo"`, "name", "text"))),
+			`<div class="field"><label class="label" for="name">Name</label><div class="control"><input name="name" placeholder="Jo" class="input" type="text" id="name"></div></div>`},
+		{Field(``, "Email", "email", errors.New("not an email address"),
+			Control(``, "@", gohtx.Raw("!"), Input(`name="addr"`, "email", "email", Small))),
+			`<div class="field"><label class="label" for="email">Email</label>` +
+				`<div class="control has-icons-left has-icons-right"><input name="addr" class="input is-small is-danger" type="email" id="email" aria-invalid="true" aria-describedby="email-help">` +
+				`<span class="icon is-small is-left">@</span><span class="icon is-small is-right">!</span></div>` +
+				`<p class="help is-danger" id="email-help">not an email address</p></div>`},
+		{Field(``, "Bio", "bio", "Too long", Help(``, "A line or two"), Control(``, nil, nil, Textarea(`rows="2"`, "bio", "Hi"))),
+			`<div class="field"><label class="label" for="bio">Bio</label><p class="help">A line or two</p>` +
+				`<div class="control"><textarea name="bio" rows="2" class="textarea is-danger" id="bio" aria-invalid="true" aria-describedby="bio-help">Hi</textarea></div>` +
+				`<p class="help is-danger" id="bio-help">Too long</p></div>`},
+		{Field(``, "Size", "size", errors.New("pick one"), Control(``, nil, nil,
+			Select(``, "size", Info, gohtx.Option(`value="s"`, "Small"), gohtx.Option(`value="l"`, "Large")))),
+			`<div class="field"><label class="label" for="size">Size</label><div class="control">` +
+				`<div class="select is-info is-danger"><select name="size" id="size" aria-invalid="true" aria-describedby="size-help"><option value="s">Small</option><option value="l">Large</option></select></div>` +
+				`</div><p class="help is-danger" id="size-help">pick one</p></div>`},
+		{Field(``, "", "", nil, Control(``, nil, nil, Checkbox(``, "terms", gohtx.BoolAttr("checked"), "I agree"))),
+			`<div class="field"><div class="control"><label class="checkbox"><input name="terms" type="checkbox" id="terms" checked> I agree</label></div></div>`},
+		{Control(``, nil, nil, Radio(``, "yes", "answer", "y", "Yes"), Radio(``, "no", "answer", "n", "No")),
+			`<div class="control"><label class="radio"><input type="radio" id="yes" name="answer" value="y"> Yes</label>` +
+				`<label class="radio"><input type="radio" id="no" name="answer" value="n"> No</label></div>`},
+		{Field(``, "Photo", "photo", errors.New("too big"), File(``, "photo", "Choose a file…", Boxed, gohtx.Attr("accept", "image/*"))),
+			`<div class="field"><label class="label" for="photo">Photo</label><div class="file is-boxed is-danger"><label class="file-label">` +
+				`<input name="photo" class="file-input" type="file" id="photo" accept="image/*" aria-invalid="true" aria-describedby="photo-help">` +
+				`<span class="file-cta"><span class="file-label">Choose a file…</span></span></label></div>` +
+				`<p class="help is-danger" id="photo-help">too big</p></div>`},
+		// Addons and grouped fields lay out several controls, and an error
+		// without an id marks them all.
+		{Field(``, "", "", "required", HasAddons,
+			Control(``, nil, nil, Input(``, "q", "search")), Control(``, nil, nil, Button(``, Info, "Search"))),
+			`<div class="field has-addons"><div class="control"><input name="q" class="input is-danger" type="search" id="q" aria-invalid="true"></div>` +
+				`<div class="control"><button class="button is-info">Search</button></div><p class="help is-danger">required</p></div>`},
+		{Field(``, "", "", nil, Grouped, Control(``, nil, nil, Button(``, Link, "Save")), Control(``, nil, nil, Button(``, Light, "Cancel"))),
+			`<div class="field is-grouped"><div class="control"><button class="button is-link">Save</button></div><div class="control"><button class="button is-light">Cancel</button></div></div>`},
+	} {
+		if got := render(t, test.h); got != test.exp {
+			t.Errorf("expected\n%s, got\n%s", test.exp, got)
+		}
+	}
+}
+
+func TestFieldDoesNotModifyControls(t *testing.T) {
+	input := Input(``, "x", "text")
+	Field(``, "X", "x", "bad", input)
+	if got := render(t, input); got != `<input name="x" class="input" type="text" id="x">` {
+		t.Errorf("Field modified its control: %s", got)
+	}
+}
@@ -0,0 +1,21 @@
+package bulma
+
+import (
+	"github.com/Michael-F-Ellis/gohtx"
+)
+
+// Hero returns a hero banner with title and subtitle, either of which may be
+// empty, followed by the content in its body.
+func Hero(a, title, subtitle string, content ...interface{}) *gohtx.HtmlTree {
+	attrs, rest := splitContent(content)
+	var body []interface{}
+	if title != "" {
+		body = append(body, gohtx.P(`class="title"`, title))
+	}
+	if subtitle != "" {
+		body = append(body, gohtx.P(`class="subtitle"`, subtitle))
+	}
+	body = append(body, rest...)
+	return element(gohtx.Section, "hero", a,
+		append(attrs, gohtx.Div(`class="hero-body"`, body...))...)
+}
@@ -0,0 +1,16 @@
+package bulma
+
+import (
+	"testing"
+)
+
+func TestHero(t *testing.T) {
+	exp := `<section class="hero is-primary is-medium"><div class="hero-body"><p class="title">Gohtx</p><p class="subtitle">htmx &amp; Bulma</p>more</div></section>`
+	if got := render(t, Hero(``, "Gohtx", "htmx & Bulma", Primary, Medium, "more")); got != exp {
+		t.Errorf("expected\n%s, got\n%s", exp, got)
+	}
+	exp = `<section class="hero"><div class="hero-body"></div></section>`
+	if got := render(t, Hero(``, "", "")); got != exp {
+		t.Errorf("expected\n%s, got\n%s", exp, got)
+	}
+}
@@ -0,0 +1,318 @@
+package gohtx
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ContentCategory is a set of the HTML5 content categories described at
+// https://html.spec.whatwg.org/multipage/dom.html#kinds-of-content
+type ContentCategory uint
+
+const (
+	MetadataContent ContentCategory = 1 << iota
+	FlowContent
+	SectioningContent
+	HeadingContent
+	PhrasingContent
+	EmbeddedContent
+	InteractiveContent
+	ScriptSupporting // script and template, permitted almost everywhere
+)
+
+// ContentModel describes where an element may appear and what it may contain.
+type ContentModel struct {
+	Categories    ContentCategory // categories the element belongs to
+	Permitted     ContentCategory // categories of content the element may contain
+	Children      []string        // other tags the element may contain
+	Parents       []string        // if not empty, the only tags that may contain the element
+	Text          bool            // text is permitted even if phrasing content isn't
+	Transparent   bool            // the element may contain whatever its parent may contain, plus Children
+	Forbidden     ContentCategory // categories that may not appear anywhere inside the element
+	ForbiddenTags []string        // tags that may not appear anywhere inside the element
+}
+
+// ContentModels is filled in at init time with the content model of each tag
+// defined in tags.go. CheckContentModel ignores tags that aren't listed, so you
+// can use custom elements freely or add entries for tags you define yourself.
+var ContentModels map[string]ContentModel
+
+// ContentModelError describes a node that violates the content model of the
+// element that contains it.
+type ContentModelError struct {
+	Path []string // path from the root to the node, e.g. [body ul[0] div[2]], where [i] is the index in the parent's content
+	Err  error
+}
+
+// Error implements the error interface.
+func (e ContentModelError) Error() string {
+	return fmt.Sprintf("%s: %v", strings.Join(e.Path, " > "), e.Err)
+}
+
+// permission describes the content permitted in a particular context.
+type permission struct {
+	any       bool            // no restrictions, e.g. at the root of a fragment
+	parent    string          // the tag of the element that contains the content
+	owner     string          // the tag whose content model applies. It differs from parent for transparent elements
+	permitted ContentCategory // permitted categories
+	children  []string        // other permitted tags
+	text      bool            // text is permitted
+}
+
+// CheckContentModel walks through an HtmlTree and checks that each element
+// is permitted where it appears, e.g. that <li> has a list as its parent, that
+// <p> contains only phrasing content and that <a> and <button> contain no
+// interactive content. It appends a ContentModelError for each offending node
+// to *perrs. The root of the tree is assumed to be permitted wherever it will
+// be placed.
+func (e *HtmlTree) CheckContentModel(perrs *[]ContentModelError) {
+	checkContentModel(e, []string{e.T}, permission{any: true}, nil, perrs)
+}
+
+// checkContentModel checks the content of h, which appears in a context
+// described by ctx, within the given ancestors.
+func checkContentModel(h *HtmlTree, path []string, ctx permission, ancestors []*HtmlTree, perrs *[]ContentModelError) {
+	report := func(path []string, format string, args ...interface{}) {
+		*perrs = append(*perrs, ContentModelError{path, fmt.Errorf(format, args...)})
+	}
+	model, known := ContentModels[h.T]
+	var inner permission
+	switch {
+	case h.T == "null":
+		inner = ctx // Null is transparent and invisible
+	case !known:
+		inner = permission{any: true, parent: h.T, owner: h.T}
+	case model.Transparent:
+		inner = ctx
+		inner.parent = h.T
+		inner.children = append(append([]string{}, ctx.children...), model.Children...)
+		if ctx.any {
+			inner = permission{any: true, parent: h.T, owner: h.T}
+		}
+	default:
+		inner = permission{
+			parent:    h.T,
+			owner:     h.T,
+			permitted: model.Permitted,
+			children:  model.Children,
+			text:      model.Text || model.Permitted&(FlowContent|PhrasingContent) != 0,
+		}
+	}
+	if h.T != "null" && known && (model.Forbidden != 0 || len(model.ForbiddenTags) > 0) {
+		ancestors = append(ancestors[:len(ancestors):len(ancestors)], h)
+	}
+	var tableRows, tableBodies bool // for detecting implied tbody elements
+	for i, c := range h.C {
+		switch c := c.(type) {
+		case string:
+			if h.empty {
+				report(path, "%s is a void element and may not have content", h.T)
+			} else if !inner.any && !inner.text && strings.TrimSpace(c) != "" {
+				report(path, "text is not permitted in %s", inner.owner)
+			}
+		case *HtmlTree:
+			if c.T == "!--" {
+				continue
+			}
+			cpath := append(path[:len(path):len(path)], fmt.Sprintf("%s[%d]", c.T, i))
+			if h.empty {
+				report(path, "%s is a void element and may not have content", h.T)
+				continue
+			}
+			if c.T != "null" {
+				if err := checkPlacement(c, inner, ancestors); err != nil {
+					*perrs = append(*perrs, ContentModelError{cpath, err})
+				}
+			}
+			switch c.T {
+			case "tr":
+				tableRows = true
+			case "tbody":
+				tableBodies = true
+			}
+			checkContentModel(c, cpath, inner, ancestors, perrs)
+		}
+	}
+	if h.T == "table" && tableRows && tableBodies {
+		report(path, "table has both tr and tbody children. The browser will wrap the tr elements in an implied tbody")
+	}
+}
+
+// checkPlacement returns an error if h isn't permitted in the context
+// described by ctx or inside one of the given ancestors.
+func checkPlacement(h *HtmlTree, ctx permission, ancestors []*HtmlTree) error {
+	model, known := ContentModels[h.T]
+	if !known {
+		return nil
+	}
+	categories := elementCategories(h, model)
+	for _, a := range ancestors {
+		amodel := ContentModels[a.T]
+		if categories&amodel.Forbidden != 0 || stringInSlice(h.T, amodel.ForbiddenTags) {
+			return fmt.Errorf("%s may not be nested inside %s", h.T, a.T)
+		}
+	}
+	switch {
+	case len(model.Parents) > 0:
+		if ctx.any && ctx.parent == "" {
+			return nil // root of a fragment
+		}
+		if !stringInSlice(ctx.parent, model.Parents) {
+			return fmt.Errorf("%s must be a child of %s, not %s", h.T, strings.Join(model.Parents, ", "), ctx.parent)
+		}
+	case ctx.any:
+	case categories&(ctx.permitted|ScriptSupporting) != 0:
+	case stringInSlice(h.T, ctx.children):
+	default:
+		return fmt.Errorf("%s is not permitted in %s", h.T, ctx.owner)
+	}
+	return nil
+}
+
+// elementCategories returns the content categories of h. A few elements
+// belong to categories that depend on their attributes.
+func elementCategories(h *HtmlTree, model ContentModel) (categories ContentCategory) {
+	categories = model.Categories
+	has := func(name string) bool {
+		_, found := attributeValue(h, name)
+		return found
+	}
+	switch h.T {
+	case "a":
+		if !has("href") {
+			categories &^= InteractiveContent
+		}
+	case "audio", "video":
+		if !has("controls") {
+			categories &^= InteractiveContent
+		}
+	case "img":
+		if has("usemap") {
+			categories |= InteractiveContent
+		}
+	case "input":
+		if v, _ := attributeValue(h, "type"); strings.EqualFold(v, "hidden") {
+			categories &^= InteractiveContent
+		}
+	}
+	return
+}
+
+func init() {
+	const (
+		flow     = FlowContent
+		phrasing = FlowContent | PhrasingContent
+		embedded = FlowContent | PhrasingContent | EmbeddedContent
+	)
+	// Derived from https://developer.mozilla.org/en-US/docs/Web/HTML/Element
+	// and the content model sections of the HTML living standard.
+	ContentModels = map[string]ContentModel{
+		// Main root and document metadata
+		"html":  {Children: []string{"head", "body"}},
+		"head":  {Permitted: MetadataContent, Parents: []string{"html"}},
+		"body":  {Permitted: flow, Parents: []string{"html"}},
+		"link":  {Categories: MetadataContent | phrasing},
+		"meta":  {Categories: MetadataContent | phrasing},
+		"title": {Categories: MetadataContent, Text: true, Parents: []string{"head"}},
+		"style": {Categories: MetadataContent | flow, Text: true},
+
+		// Sectioning
+		"address": {Categories: flow, Permitted: flow, Forbidden: HeadingContent | SectioningContent, ForbiddenTags: []string{"header", "footer", "address"}},
+		"article": {Categories: flow | SectioningContent, Permitted: flow},
+		"aside":   {Categories: flow | SectioningContent, Permitted: flow},
+		"footer":  {Categories: flow, Permitted: flow, ForbiddenTags: []string{"header", "footer"}},
+		"header":  {Categories: flow, Permitted: flow, ForbiddenTags: []string{"header", "footer"}},
+		"h1":      {Categories: flow | HeadingContent, Permitted: PhrasingContent},
+		"h2":      {Categories: flow | HeadingContent, Permitted: PhrasingContent},
+		"h3":      {Categories: flow | HeadingContent, Permitted: PhrasingContent},
+		"h4":      {Categories: flow | HeadingContent, Permitted: PhrasingContent},
+		"h5":      {Categories: flow | HeadingContent, Permitted: PhrasingContent},
+		"h6":      {Categories: flow | HeadingContent, Permitted: PhrasingContent},
+		"nav":     {Categories: flow | SectioningContent, Permitted: flow},
+		"section": {Categories: flow | SectioningContent, Permitted: flow},
+		"details": {Categories: flow | InteractiveContent, Permitted: flow, Children: []string{"summary"}},
+		"summary": {Permitted: PhrasingContent | HeadingContent, Parents: []string{"details"}},
+
+		// Text content
+		"blockquote": {Categories: flow, Permitted: flow},
+		"dd":         {Permitted: flow, Parents: []string{"dl", "div"}},
+		"div":        {Categories: flow, Permitted: flow},
+		"dl":         {Categories: flow, Children: []string{"dt", "dd", "div"}},
+		"dt":         {Permitted: flow, Parents: []string{"dl", "div"}, Forbidden: HeadingContent | SectioningContent, ForbiddenTags: []string{"header", "footer"}},
+		"figcaption": {Permitted: flow, Parents: []string{"figure"}},
+		"figure":     {Categories: flow, Permitted: flow, Children: []string{"figcaption"}},
+		"hr":         {Categories: flow},
+		"li":         {Permitted: flow, Parents: []string{"ul", "ol", "menu"}},
+		"main":       {Categories: flow, Permitted: flow},
+		"ol":         {Categories: flow, Children: []string{"li"}},
+		"p":          {Categories: flow, Permitted: PhrasingContent},
+		"pre":        {Categories: flow, Permitted: PhrasingContent},
+		"ul":         {Categories: flow, Children: []string{"li"}},
+
+		// Inline text semantics
+		"a":      {Categories: phrasing | InteractiveContent, Transparent: true, Forbidden: InteractiveContent, ForbiddenTags: []string{"a"}},
+		"b":      {Categories: phrasing, Permitted: PhrasingContent},
+		"br":     {Categories: phrasing},
+		"cite":   {Categories: phrasing, Permitted: PhrasingContent},
+		"code":   {Categories: phrasing, Permitted: PhrasingContent},
+		"em":     {Categories: phrasing, Permitted: PhrasingContent},
+		"i":      {Categories: phrasing, Permitted: PhrasingContent},
+		"s":      {Categories: phrasing, Permitted: PhrasingContent},
+		"samp":   {Categories: phrasing, Permitted: PhrasingContent},
+		"small":  {Categories: phrasing, Permitted: PhrasingContent},
+		"span":   {Categories: phrasing, Permitted: PhrasingContent},
+		"strong": {Categories: phrasing, Permitted: PhrasingContent},
+		"sub":    {Categories: phrasing, Permitted: PhrasingContent},
+		"sup":    {Categories: phrasing, Permitted: PhrasingContent},
+		"u":      {Categories: phrasing, Permitted: PhrasingContent},
+
+		// Image and multimedia
+		"area":  {Categories: phrasing},
+		"audio": {Categories: embedded | InteractiveContent, Transparent: true, Children: []string{"source", "track"}, ForbiddenTags: []string{"audio", "video"}},
+		"img":   {Categories: embedded},
+		"map":   {Categories: phrasing, Transparent: true, Children: []string{"area"}},
+		"track": {Parents: []string{"audio", "video"}},
+		"video": {Categories: embedded | InteractiveContent, Transparent: true, Children: []string{"source", "track"}, ForbiddenTags: []string{"audio", "video"}},
+
+		// Embedded content
+		"embed":  {Categories: embedded | InteractiveContent},
+		"object": {Categories: embedded, Transparent: true, Children: []string{"param"}},
+		"param":  {Parents: []string{"object"}},
+		"source": {Parents: []string{"audio", "video", "picture"}},
+
+		// Scripting
+		"canvas":   {Categories: embedded, Transparent: true, Forbidden: InteractiveContent},
+		"noscript": {Categories: MetadataContent | phrasing, Transparent: true, ForbiddenTags: []string{"noscript"}},
+		"script":   {Categories: MetadataContent | phrasing | ScriptSupporting, Text: true},
+
+		// Table content
+		"caption": {Permitted: flow, Parents: []string{"table"}, ForbiddenTags: []string{"table"}},
+		"col":     {Parents: []string{"colgroup", "table"}},
+		"table":   {Categories: flow, Children: []string{"caption", "colgroup", "col", "thead", "tbody", "tfoot", "tr"}},
+		"tbody":   {Children: []string{"tr"}, Parents: []string{"table"}},
+		"td":      {Permitted: flow, Parents: []string{"tr"}},
+		"tfoot":   {Children: []string{"tr"}, Parents: []string{"table"}},
+		"th":      {Permitted: flow, Parents: []string{"tr"}, Forbidden: HeadingContent | SectioningContent, ForbiddenTags: []string{"header", "footer"}},
+		"thead":   {Children: []string{"tr"}, Parents: []string{"table"}},
+		"tr":      {Children: []string{"td", "th"}, Parents: []string{"table", "thead", "tbody", "tfoot"}},
+
+		// Forms
+		"button":   {Categories: phrasing | InteractiveContent, Permitted: PhrasingContent, Forbidden: InteractiveContent},
+		"datalist": {Categories: phrasing, Permitted: PhrasingContent, Children: []string{"option"}},
+		"fieldset": {Categories: flow, Permitted: flow, Children: []string{"legend"}},
+		"form":     {Categories: flow, Permitted: flow, ForbiddenTags: []string{"form"}},
+		"input":    {Categories: phrasing | InteractiveContent},
+		"label":    {Categories: phrasing | InteractiveContent, Permitted: PhrasingContent, ForbiddenTags: []string{"label"}},
+		"legend":   {Permitted: PhrasingContent | HeadingContent, Parents: []string{"fieldset"}},
+		"meter":    {Categories: phrasing, Permitted: PhrasingContent, ForbiddenTags: []string{"meter"}},
+		"optgroup": {Children: []string{"option"}, Parents: []string{"select"}},
+		"option":   {Text: true, Parents: []string{"select", "datalist", "optgroup"}},
+		"output":   {Categories: phrasing, Permitted: PhrasingContent},
+		"progress": {Categories: phrasing, Permitted: PhrasingContent, ForbiddenTags: []string{"progress"}},
+		"select":   {Categories: phrasing | InteractiveContent, Children: []string{"option", "optgroup"}},
+		"textarea": {Categories: phrasing | InteractiveContent, Text: true},
+
+		// Interactive elements
+		"dialog": {Categories: flow, Permitted: flow},
+	}
+}
@@ -0,0 +1,70 @@
+package gohtx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckContentModel(t *testing.T) {
+	// These trees should not produce errors
+	valid := []*HtmlTree{
+		Null(Raw("<!DOCTYPE html>"), Html(``, Head(``, DefaultHeadContent(), Title(``, "x")), Body(``, P(``, "hello")))),
+		Ul(``, Li(``, "one"), Null(Li(``, "two"), Li(``, Div(``, "three")))),
+		P(``, "text ", B(``, "bold"), A(`href=/x`, Span(``, "link")), Br(``)),
+		Table(``, Tr(``, Td(``, "cell"))),
+		Table(``, Thead(``, Tr(``, Th(``, "h"))), Tbody(``, Tr(``, Td(``, "cell")))),
+		A(`href=/x`, Div(``, "a may contain flow content outside of p")),
+		Button(``, A(``, "a without href isn't interactive")),
+		Form(``, Label(``, "Name", Input(`type=text`)), Input(`type=hidden`)),
+		Dl(``, Div(``, Dt(``, "term"), Dd(``, "definition"))),
+		Select(``, Option(``, "one"), Optgroup(``, Option(``, "two"))),
+		Div(``, Comment("comments are ignored"), "  "),
+		Li(``, "the root of a fragment may be anything"),
+	}
+	for _, tree := range valid {
+		perrs := &[]ContentModelError{}
+		tree.CheckContentModel(perrs)
+		if len(*perrs) != 0 {
+			t.Errorf("unexpected errors %v", *perrs)
+		}
+	}
+
+	type testcase struct {
+		tree *HtmlTree
+		path string // expected path of the single error
+		msg  string // expected substring of the error message
+	}
+	invalid := []testcase{
+		{Div(``, Li(``, "orphan")), "div > li[0]", "li must be a child of ul, ol, menu, not div"},
+		{P(``, Div(``, "block")), "p > div[0]", "div is not permitted in p"},
+		{P(``, A(`href=/x`, Div(``, "block"))), "p > a[0] > div[0]", "div is not permitted in p"},
+		{A(`href=/x`, Button(``, "b")), "a > button[0]", "button may not be nested inside a"},
+		{Button(``, Span(``, Input(`type=text`))), "button > span[0] > input[0]", "input may not be nested inside button"},
+		{Table(``, Tr(``, Td(``)), Tbody(``)), "table", "implied tbody"},
+		{Ul(``, "text"), "ul", "text is not permitted in ul"},
+		{Body(``, Ul(``, Null(Div(``)))), "body > ul[0] > null[0] > div[0]", "div is not permitted in ul"},
+		{Br(``).With(Attrs{"id": "x"}), "", ""}, // attributes aren't content
+		{&HtmlTree{"br", "", []interface{}{"x"}, true}, "br", "void element"},
+	}
+	for _, tc := range invalid {
+		perrs := &[]ContentModelError{}
+		tc.tree.CheckContentModel(perrs)
+		if tc.path == "" {
+			if len(*perrs) != 0 {
+				t.Errorf("unexpected errors %v", *perrs)
+			}
+			continue
+		}
+		if len(*perrs) != 1 {
+			t.Errorf("expected 1 error at %s, got %v", tc.path, *perrs)
+			continue
+		}
+		e := (*perrs)[0]
+		if path := strings.Join(e.Path, " > "); path != tc.path {
+			t.Errorf("expected path %s, got %s", tc.path, path)
+		}
+		if !strings.Contains(e.Error(), tc.msg) {
+			t.Errorf("expected %q in %q", tc.msg, e.Error())
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// head-support: merges the <head> of responses into the page's head.
+// Compatible with the htmx head-support extension's attributes. Part of gohtx.
+//
+//	<body hx-ext="head-support">
+//
+// Responses to boosted requests are merged: elements already in the head are
+// kept, new ones are added and ones missing from the response are removed.
+// Other responses only add new elements. Set hx-head="merge" or
+// hx-head="append" on the response's <head> to choose explicitly. Elements
+// with hx-preserve="true" are never removed and ones with hx-head="re-eval"
+// are always re-added, which re-runs scripts. The htmx:addingHeadElement and
+// htmx:removingHeadElement events may be cancelled to veto a change.
+(function () {
+	var api;
+
+	htmx.defineExtension("head-support", {
+		init: function (apiRef) {
+			api = apiRef;
+		},
+		onEvent: function (name, evt) {
+			if (name !== "htmx:afterSwap" || !evt.detail.xhr) {
+				return;
+			}
+			var response = evt.detail.xhr.response;
+			if (typeof response !== "string" || !/<head(\s[^>]*)?>/i.test(response)) {
+				return;
+			}
+			var head = new DOMParser().parseFromString(response, "text/html").head;
+			var source = evt.detail.requestConfig && evt.detail.requestConfig.elt;
+			var boosted = source && api.getInternalData(source).boosted;
+			var mode = head.getAttribute("hx-head") || (boosted ? "merge" : "append");
+			mergeHead(head, mode);
+		}
+	});
+
+	function mergeHead(newHead, mode) {
+		var current = document.head;
+		var wanted = {}; // outerHTML of new elements -> element
+		var order = [];
+		Array.prototype.forEach.call(newHead.children, function (elt) {
+			wanted[elt.outerHTML] = elt;
+			order.push(elt.outerHTML);
+		});
+		var removed = [];
+		Array.prototype.forEach.call(current.children, function (elt) {
+			var html = elt.outerHTML;
+			var match = wanted[html];
+			if (match && match.getAttribute("hx-head") !== "re-eval") {
+				delete wanted[html]; // already present, keep it
+				return;
+			}
+			if (match) {
+				removed.push(elt); // re-eval: replace it with a fresh copy
+				return;
+			}
+			if (mode === "merge" && elt.getAttribute("hx-preserve") !== "true" && elt.tagName !== "TITLE") {
+				removed.push(elt);
+			}
+		});
+		removed.forEach(function (elt) {
+			if (api.triggerEvent(document.body, "htmx:removingHeadElement", { headElement: elt }) !== false) {
+				current.removeChild(elt);
+			}
+		});
+		order.forEach(function (html) {
+			var elt = wanted[html];
+			if (!elt || elt.tagName === "TITLE") {
+				return; // htmx core handles the title
+			}
+			// A fragment made by a range runs scripts when it's inserted.
+			var fresh = document.createRange().createContextualFragment(html).firstElementChild;
+			if (api.triggerEvent(document.body, "htmx:addingHeadElement", { headElement: fresh }) !== false) {
+				current.appendChild(fresh);
+			}
+		});
+	}
+})();
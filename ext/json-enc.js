@@ -0,0 +1,13 @@
+// json-enc: encodes request parameters as JSON instead of form data.
+// Compatible with the htmx json-enc extension. Part of gohtx.
+htmx.defineExtension("json-enc", {
+	onEvent: function (name, evt) {
+		if (name === "htmx:configRequest") {
+			evt.detail.headers["Content-Type"] = "application/json";
+		}
+	},
+	encodeParameters: function (xhr, parameters, elt) {
+		xhr.overrideMimeType("text/json");
+		return JSON.stringify(parameters);
+	}
+});
@@ -0,0 +1,150 @@
+// loading-states: changes elements while a request is in flight. Compatible
+// with the htmx loading-states extension's attributes. Part of gohtx.
+//
+//	<form hx-ext="loading-states" data-loading-states hx-post="/save">
+//	  <button data-loading-disable data-loading-class="is-loading">Save</button>
+//	  <span data-loading data-loading-delay="500ms">Saving...</span>
+//	</form>
+//
+// The states apply to elements inside the closest data-loading-states
+// ancestor of the element making the request, or the whole body if there is
+// none:
+//
+//	data-loading[="display"]   shows a hidden element, as inline-block by default
+//	data-loading-class         adds classes
+//	data-loading-class-remove  removes classes
+//	data-loading-disable       disables the element
+//	data-loading-aria-busy     sets aria-busy="true"
+//	data-loading-delay[="ms"]  waits before applying the states, 200ms by default
+//	data-loading-target        applies the states to the selected elements instead
+//	data-loading-path          applies the states only to requests for that path
+(function () {
+	var api;
+	var undos = []; // functions that revert the states applied for the current requests
+
+	htmx.defineExtension("loading-states", {
+		init: function (apiRef) {
+			api = apiRef;
+			// Hide data-loading elements until a request shows them.
+			var style = document.createElement("style");
+			style.textContent = "[data-loading]{display:none}";
+			document.head.appendChild(style);
+		},
+		onEvent: function (name, evt) {
+			if (name === "htmx:beforeRequest") {
+				var container = api.getClosestMatch(evt.target, function (e) {
+					return e.hasAttribute && e.hasAttribute("data-loading-states");
+				}) || document.body;
+				var path = evt.detail.requestConfig ? evt.detail.requestConfig.path : null;
+				apply(container, path);
+			}
+			if (name === "htmx:beforeOnLoad" || name === "htmx:afterRequest" && !evt.detail.successful) {
+				while (undos.length > 0) {
+					undos.pop()();
+				}
+			}
+		}
+	});
+
+	var states = {
+		"data-loading": function (elt, value) {
+			var display = elt.style.display;
+			elt.style.display = value || "inline-block";
+			return function () {
+				elt.style.display = display;
+			};
+		},
+		"data-loading-class": function (elt, value) {
+			var classes = value.split(/\s+/).filter(Boolean);
+			classes.forEach(function (c) {
+				elt.classList.add(c);
+			});
+			return function () {
+				classes.forEach(function (c) {
+					elt.classList.remove(c);
+				});
+			};
+		},
+		"data-loading-class-remove": function (elt, value) {
+			var classes = value.split(/\s+/).filter(Boolean);
+			classes.forEach(function (c) {
+				elt.classList.remove(c);
+			});
+			return function () {
+				classes.forEach(function (c) {
+					elt.classList.add(c);
+				});
+			};
+		},
+		"data-loading-disable": function (elt) {
+			elt.disabled = true;
+			return function () {
+				elt.disabled = false;
+			};
+		},
+		"data-loading-aria-busy": function (elt) {
+			elt.setAttribute("aria-busy", "true");
+			return function () {
+				elt.removeAttribute("aria-busy");
+			};
+		}
+	};
+
+	// apply queues the states of the elements in container.
+	function apply(container, path) {
+		Object.keys(states).forEach(function (attr) {
+			var elts = Array.prototype.slice.call(container.querySelectorAll("[" + attr + "]"));
+			if (container.hasAttribute(attr)) {
+				elts.push(container);
+			}
+			elts.forEach(function (elt) {
+				if (elt.hasAttribute("data-loading-path") && elt.getAttribute("data-loading-path") !== path) {
+					return;
+				}
+				var targets = [elt];
+				if (elt.hasAttribute("data-loading-target")) {
+					targets = Array.prototype.slice.call(document.querySelectorAll(elt.getAttribute("data-loading-target")));
+				}
+				targets.forEach(function (target) {
+					queue(elt, function () {
+						return states[attr](target, elt.getAttribute(attr));
+					});
+				});
+			});
+		});
+	}
+
+	// queue applies a state now, or after the element's data-loading-delay,
+	// and records how to undo it.
+	function queue(elt, applyState) {
+		var delay = 0;
+		if (elt.hasAttribute("data-loading-delay")) {
+			delay = parseInterval(elt.getAttribute("data-loading-delay")) || 200;
+		}
+		if (delay === 0) {
+			undos.push(applyState());
+			return;
+		}
+		var undo = null;
+		var timer = setTimeout(function () {
+			undo = applyState();
+		}, delay);
+		undos.push(function () {
+			clearTimeout(timer);
+			if (undo) {
+				undo();
+			}
+		});
+	}
+
+	// parseInterval returns the milliseconds in values like "500ms" or "1s".
+	function parseInterval(value) {
+		if (/ms$/.test(value)) {
+			return parseFloat(value);
+		}
+		if (/s$/.test(value)) {
+			return parseFloat(value) * 1000;
+		}
+		return parseFloat(value);
+	}
+})();
@@ -0,0 +1,121 @@
+// preload: fetches linked content before the user clicks, so it's in the
+// browser's cache when they do. Compatible with the htmx preload extension's
+// attributes. Part of gohtx.
+//
+//	<body hx-ext="preload">
+//	  <a href="/about" preload>About</a>
+//	  <ul preload="mouseover"><li><a hx-get="/a">A</a></li></ul>
+//	</body>
+//
+// The preload attribute applies to the element itself and to the links and
+// hx-get elements inside it. Its value is the event that starts the preload:
+// "mousedown" (the default), "mouseover", which waits 100ms so that passing
+// over a link doesn't load it, "init" to load immediately, or any other event.
+// With preload-images="true", images in the preloaded content are fetched too.
+// Responses must be cacheable for the preload to help.
+(function () {
+	var api;
+
+	htmx.defineExtension("preload", {
+		init: function (apiRef) {
+			api = apiRef;
+		},
+		onEvent: function (name, evt) {
+			if (name !== "htmx:afterProcessNode") {
+				return;
+			}
+			var root = evt.target;
+			if (!root.querySelectorAll) {
+				return;
+			}
+			var nodes = Array.prototype.slice.call(root.querySelectorAll("[preload]"));
+			if (root.hasAttribute("preload")) {
+				nodes.push(root);
+			}
+			nodes.forEach(function (node) {
+				setup(node);
+				var links = node.querySelectorAll("a[href], [hx-get], [data-hx-get]");
+				for (var i = 0; i < links.length; i++) {
+					setup(links[i]);
+				}
+			});
+		}
+	});
+
+	// attribute returns the value of name on node or its closest ancestor.
+	function attribute(node, name) {
+		var elt = api.getClosestMatch(node, function (e) {
+			return e.hasAttribute && e.hasAttribute(name);
+		});
+		return elt ? elt.getAttribute(name) : null;
+	}
+
+	// setup adds the listener that preloads node.
+	function setup(node) {
+		var data = api.getInternalData(node);
+		if (data.preloadState !== undefined) {
+			return;
+		}
+		var url = api.getAttributeValue(node, "hx-get") || node.getAttribute("href");
+		if (!url || node.getAttribute("hx-boost") === "false") {
+			return;
+		}
+		data.preloadState = "READY";
+		var on = attribute(node, "preload") || "mousedown";
+		if (on === "init") {
+			load(node, url);
+			return;
+		}
+		var timer = null;
+		node.addEventListener(on, function () {
+			if (on === "mouseover") {
+				timer = setTimeout(function () {
+					load(node, url);
+				}, 100);
+			} else {
+				load(node, url);
+			}
+		});
+		if (on === "mouseover") {
+			node.addEventListener("mouseout", function () {
+				clearTimeout(timer);
+			});
+			// Touch screens have no hover, so preload on touch instead.
+			node.addEventListener("touchstart", function () {
+				load(node, url);
+			}, { passive: true });
+		}
+	}
+
+	// load fetches url once, with the headers htmx would send for node.
+	function load(node, url) {
+		var data = api.getInternalData(node);
+		if (data.preloadState !== "READY") {
+			return;
+		}
+		data.preloadState = "RUNNING";
+		var xhr = new XMLHttpRequest();
+		xhr.open("GET", url);
+		if (node.hasAttribute("hx-get") || node.hasAttribute("data-hx-get") || api.getInternalData(node).boosted) {
+			var headers = api.getHeaders(node, api.getTarget(node));
+			for (var h in headers) {
+				if (headers.hasOwnProperty(h) && headers[h] !== null && headers[h] !== undefined) {
+					xhr.setRequestHeader(h, headers[h]);
+				}
+			}
+		}
+		xhr.onload = function () {
+			data.preloadState = "DONE";
+			if (attribute(node, "preload-images") === "true") {
+				var images = api.makeFragment(xhr.responseText).querySelectorAll("img");
+				for (var i = 0; i < images.length; i++) {
+					new Image().src = images[i].src;
+				}
+			}
+		};
+		xhr.onerror = function () {
+			data.preloadState = "READY";
+		};
+		xhr.send();
+	}
+})();
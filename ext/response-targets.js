@@ -0,0 +1,90 @@
+// response-targets: swaps error responses into targets chosen by status code.
+// Compatible with the htmx response-targets extension's attributes. Part of
+// gohtx.
+//
+//	<div hx-ext="response-targets">
+//	  <form hx-post="/save" hx-target="#result" hx-target-422="#errors" hx-target-5*="#alert">
+//
+// For a response with a status other than 200, the extension looks up the
+// element's ancestors for hx-target-<code>, where trailing digits may be
+// replaced by "*" or "x", e.g. hx-target-404, hx-target-40*, hx-target-4xx,
+// then hx-target-* and, for 4xx and 5xx responses, hx-target-error. The value
+// is a CSS selector or one of "this", "closest <selector>", "find <selector>".
+// A matching response is swapped in, and no longer treated as an error, even
+// though htmx doesn't swap error responses by default.
+(function () {
+	var api;
+	var prefix = "hx-target-";
+
+	htmx.defineExtension("response-targets", {
+		init: function (apiRef) {
+			api = apiRef;
+		},
+		onEvent: function (name, evt) {
+			if (name !== "htmx:beforeSwap" || !evt.detail.xhr || evt.detail.xhr.status === 200) {
+				return true;
+			}
+			if (!evt.detail.requestConfig) {
+				return true;
+			}
+			var target = statusTarget(evt.detail.requestConfig.elt, evt.detail.xhr.status);
+			if (target) {
+				evt.detail.isError = false;
+				evt.detail.shouldSwap = true;
+				evt.detail.target = target;
+			}
+			return true;
+		}
+	});
+
+	// candidates returns the attribute names that may select the target for
+	// status, most specific first.
+	function candidates(status) {
+		var code = String(status);
+		var names = [code];
+		for (var i = code.length - 1; i > 0; i--) {
+			var stem = code.substr(0, i);
+			var n = code.length - i;
+			names.push(stem + "*", stem + "x", stem + repeat("*", n), stem + repeat("x", n));
+		}
+		names.push("*", "x", "***", "xxx");
+		if (code.charAt(0) === "4" || code.charAt(0) === "5") {
+			names.push("error");
+		}
+		return names;
+	}
+
+	function repeat(s, n) {
+		return new Array(n + 1).join(s);
+	}
+
+	// statusTarget returns the element selected by the closest matching
+	// hx-target-* attribute of elt, or null.
+	function statusTarget(elt, status) {
+		var names = candidates(status);
+		for (var i = 0; i < names.length; i++) {
+			var attr = prefix + names[i];
+			var owner = api.getClosestMatch(elt, function (e) {
+				return api.hasAttribute(e, attr);
+			});
+			if (owner) {
+				return select(owner, api.getAttributeValue(owner, attr));
+			}
+		}
+		return null;
+	}
+
+	// select resolves an extended selector relative to elt.
+	function select(elt, selector) {
+		if (selector === "this") {
+			return elt;
+		}
+		if (selector.indexOf("closest ") === 0) {
+			return elt.closest(selector.substr(8));
+		}
+		if (selector.indexOf("find ") === 0) {
+			return elt.querySelector(selector.substr(5));
+		}
+		return document.querySelector(selector);
+	}
+})();
@@ -0,0 +1,115 @@
+// sse: connects elements to Server-Sent Event streams. Compatible with the
+// htmx sse extension's attributes. Part of gohtx.
+//
+//	<div hx-ext="sse" sse-connect="/events">
+//	  <div sse-swap="progress"></div>          swaps in the data of "progress" events
+//	  <div hx-get="/done" hx-trigger="sse:done"></div>
+//	</div>
+//
+// The EventSource is kept where htmx core looks for it, so hx-trigger="sse:name"
+// works and the source is closed when its element is removed. If the server
+// closes the stream, the extension reconnects with exponential backoff.
+(function () {
+	var api;
+
+	htmx.defineExtension("sse", {
+		init: function (apiRef) {
+			api = apiRef;
+			if (htmx.createEventSource === undefined) {
+				htmx.createEventSource = function (url) {
+					return new EventSource(url, { withCredentials: true });
+				};
+			}
+		},
+		onEvent: function (name, evt) {
+			if (name === "htmx:afterProcessNode") {
+				var elt = evt.target;
+				if (elt.hasAttribute && elt.hasAttribute("sse-connect")) {
+					connect(elt, elt.getAttribute("sse-connect"), 0);
+				}
+				if (elt.hasAttribute && elt.hasAttribute("sse-swap")) {
+					addSwapListeners(elt);
+				}
+				if (elt.querySelectorAll) {
+					var swaps = elt.querySelectorAll("[sse-swap]");
+					for (var i = 0; i < swaps.length; i++) {
+						addSwapListeners(swaps[i]);
+					}
+				}
+			}
+		}
+	});
+
+	// connect opens an EventSource for elt and retries, with backoff, when
+	// the browser gives up on it.
+	function connect(elt, url, retries) {
+		if (!api.bodyContains(elt)) {
+			return;
+		}
+		var source = htmx.createEventSource(url);
+		source.onopen = function () {
+			retries = 0;
+		};
+		source.onerror = function (err) {
+			api.triggerErrorEvent(elt, "htmx:sseError", { error: err, source: source });
+			if (!api.bodyContains(elt)) {
+				source.close();
+				return;
+			}
+			if (source.readyState === EventSource.CLOSED) {
+				var delay = Math.min(1000 * Math.pow(2, Math.min(retries, 6)), 64000);
+				setTimeout(function () {
+					connect(elt, url, retries + 1);
+				}, delay);
+			}
+		};
+		api.getInternalData(elt).sseEventSource = source;
+		// Elements that were processed before the source opened listen now.
+		var swaps = elt.querySelectorAll("[sse-swap]");
+		for (var i = 0; i < swaps.length; i++) {
+			api.getInternalData(swaps[i]).sseSwapSource = null;
+			addSwapListeners(swaps[i]);
+		}
+	}
+
+	// addSwapListeners swaps the data of the events named in elt's sse-swap
+	// attribute into elt, following its hx-swap attribute.
+	function addSwapListeners(elt) {
+		var sourceElt = api.getClosestMatch(elt, function (e) {
+			return api.getInternalData(e).sseEventSource != null;
+		});
+		if (sourceElt == null) {
+			return; // connect will add the listeners once the source exists
+		}
+		var source = api.getInternalData(sourceElt).sseEventSource;
+		var data = api.getInternalData(elt);
+		if (data.sseSwapSource === source) {
+			return;
+		}
+		data.sseSwapSource = source;
+		var names = elt.getAttribute("sse-swap").split(",");
+		for (var i = 0; i < names.length; i++) {
+			addSwapListener(elt, source, names[i].trim());
+		}
+	}
+
+	function addSwapListener(elt, source, eventName) {
+		var listener = function (event) {
+			if (!api.bodyContains(elt)) {
+				source.removeEventListener(eventName, listener);
+				return;
+			}
+			var content = event.data;
+			api.withExtensions(elt, function (extension) {
+				content = extension.transformResponse(content, null, elt);
+			});
+			var swapSpec = api.getSwapSpecification(elt);
+			var target = api.getTarget(elt);
+			var settleInfo = api.makeSettleInfo(elt);
+			api.selectAndSwap(swapSpec.swapStyle, elt, target, content, settleInfo);
+			api.settleImmediately(settleInfo.tasks);
+			api.triggerEvent(elt, "htmx:sseMessage", event);
+		};
+		source.addEventListener(eventName, listener);
+	}
+})();
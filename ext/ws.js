@@ -0,0 +1,143 @@
+// ws: connects elements to WebSockets. Compatible with the htmx ws
+// extension's attributes. Part of gohtx.
+//
+//	<div hx-ext="ws" ws-connect="/chat">
+//	  <div id="messages"></div>
+//	  <form id="say" ws-send><input name="text"></form>
+//	</div>
+//
+// Each message received is parsed as html and its top-level elements are
+// swapped in out-of-band, by id or by their hx-swap-oob attributes. Elements
+// with ws-send send their values, plus the request headers htmx would send
+// under "HEADERS", as JSON when they're triggered. The socket is kept where
+// htmx core looks for it, so it's closed when its element is removed, and it
+// reconnects after abnormal closures following htmx.config.wsReconnectDelay.
+(function () {
+	var api;
+
+	htmx.defineExtension("ws", {
+		init: function (apiRef) {
+			api = apiRef;
+			if (htmx.createWebSocket === undefined) {
+				htmx.createWebSocket = function (url) {
+					return new WebSocket(url, []);
+				};
+			}
+			if (htmx.config.wsReconnectDelay === undefined) {
+				htmx.config.wsReconnectDelay = "full-jitter";
+			}
+		},
+		onEvent: function (name, evt) {
+			if (name === "htmx:afterProcessNode") {
+				var elt = evt.target;
+				if (elt.hasAttribute && elt.hasAttribute("ws-connect")) {
+					connect(elt, elt.getAttribute("ws-connect"), 0);
+				}
+				if (elt.hasAttribute && elt.hasAttribute("ws-send")) {
+					addSendListener(elt);
+				}
+				if (elt.querySelectorAll) {
+					var senders = elt.querySelectorAll("[ws-send]");
+					for (var i = 0; i < senders.length; i++) {
+						addSendListener(senders[i]);
+					}
+				}
+			}
+		}
+	});
+
+	// connect opens a WebSocket for elt. Relative URLs are resolved against the
+	// page's host with the ws or wss scheme matching the page.
+	function connect(elt, url, retries) {
+		if (!api.bodyContains(elt)) {
+			return;
+		}
+		if (url.indexOf("/") === 0) {
+			var host = location.hostname + (location.port ? ":" + location.port : "");
+			url = (location.protocol === "https:" ? "wss://" : "ws://") + host + url;
+		}
+		var socket = htmx.createWebSocket(url);
+		socket.onopen = function () {
+			retries = 0;
+		};
+		socket.onerror = function (err) {
+			api.triggerErrorEvent(elt, "htmx:wsError", { error: err, socket: socket });
+			if (!api.bodyContains(elt)) {
+				socket.close();
+			}
+		};
+		socket.onclose = function (event) {
+			// 1006: abnormal closure, 1012: service restart, 1013: try again later
+			if ([1006, 1012, 1013].indexOf(event.code) >= 0 && api.bodyContains(elt)) {
+				setTimeout(function () {
+					connect(elt, url, retries + 1);
+				}, reconnectDelay(retries));
+			}
+		};
+		socket.addEventListener("message", function (event) {
+			if (!api.bodyContains(elt)) {
+				socket.close();
+				return;
+			}
+			var content = event.data;
+			api.withExtensions(elt, function (extension) {
+				content = extension.transformResponse(content, null, elt);
+			});
+			var settleInfo = api.makeSettleInfo(elt);
+			var fragment = api.makeFragment(content);
+			var children = Array.prototype.slice.call(fragment.children);
+			for (var i = 0; i < children.length; i++) {
+				api.oobSwap(api.getAttributeValue(children[i], "hx-swap-oob") || "true", children[i], settleInfo);
+			}
+			api.settleImmediately(settleInfo.tasks);
+		});
+		api.getInternalData(elt).webSocket = socket;
+	}
+
+	// reconnectDelay returns the milliseconds to wait before the next attempt.
+	function reconnectDelay(retries) {
+		var delay = htmx.config.wsReconnectDelay;
+		if (typeof delay === "function") {
+			return delay(retries);
+		}
+		if (delay === "full-jitter") {
+			var max = 1000 * Math.pow(2, Math.min(retries, 6));
+			return max * Math.random();
+		}
+		return 1000;
+	}
+
+	// addSendListener sends elt's values over the closest socket when elt is
+	// triggered.
+	function addSendListener(elt) {
+		var data = api.getInternalData(elt);
+		if (data.wsSendListener) {
+			return;
+		}
+		var trigger = api.getTriggerSpecs(elt)[0].trigger;
+		data.wsSendListener = function (evt) {
+			var socketElt = api.getClosestMatch(elt, function (e) {
+				return api.getInternalData(e).webSocket != null;
+			});
+			if (socketElt == null) {
+				api.triggerErrorEvent(elt, "htmx:noWebSocketSourceError");
+				return;
+			}
+			var socket = api.getInternalData(socketElt).webSocket;
+			var headers = api.getHeaders(elt, socketElt);
+			var results = api.getInputValues(elt, "post");
+			if (results.errors && results.errors.length > 0) {
+				api.triggerEvent(elt, "htmx:validation:halted", results.errors);
+				return;
+			}
+			var values = api.mergeObjects(results.values, api.getExpressionVars(elt));
+			var body = api.filterValues(values, elt);
+			body["HEADERS"] = headers;
+			socket.send(JSON.stringify(body));
+			if (api.shouldCancel(evt, elt)) {
+				evt.preventDefault();
+			}
+		};
+		elt.addEventListener(trigger, data.wsSendListener);
+	}
+})();
@@ -0,0 +1,199 @@
+package gohtx
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Extension describes an htmx extension whose script is embedded in GohtxFS.
+// Scripts for the extensions are loaded with Assets.ExtensionScripts, and
+// elements opt in with hx-ext, e.g. Div(`hx-ext="sse" sse-connect="/events"`).
+type Extension struct {
+	Name string // the name used in hx-ext, e.g. "sse"
+	File string // the path of the script in GohtxFS
+
+	// Attributes maps the names of the attributes the extension adds to the
+	// grammars of their values. A nil grammar accepts any value.
+	Attributes map[string]ValueGrammar
+
+	// Match, if not nil, recognizes a family of attribute names too large
+	// to list, like hx-target-404 and hx-target-5xx. It returns the grammar
+	// for the attribute's value and whether the name belongs to the family.
+	Match func(name string) (ValueGrammar, bool)
+}
+
+// Extensions lists the embedded htmx extensions by name. The scripts are
+// written for gohtx against the extension API of the embedded htmx and accept
+// the same attributes as the htmx extensions of the same names.
+var Extensions = map[string]*Extension{
+	"head-support": {
+		Name:       "head-support",
+		File:       "ext/head-support.js",
+		Attributes: map[string]ValueGrammar{"hx-head": EnumValue("merge", "append", "re-eval")},
+	},
+	"json-enc": {
+		Name: "json-enc",
+		File: "ext/json-enc.js",
+	},
+	// loading-states uses data-loading-* attributes, which are always valid.
+	"loading-states": {
+		Name: "loading-states",
+		File: "ext/loading-states.js",
+	},
+	"preload": {
+		Name: "preload",
+		File: "ext/preload.js",
+		Attributes: map[string]ValueGrammar{
+			"preload":        nil, // the event that starts the preload
+			"preload-images": EnumValue("true", "false"),
+		},
+	},
+	"response-targets": {
+		Name:  "response-targets",
+		File:  "ext/response-targets.js",
+		Match: matchResponseTarget,
+	},
+	"sse": {
+		Name: "sse",
+		File: "ext/sse.js",
+		Attributes: map[string]ValueGrammar{
+			"sse-connect": URLValue,
+			"sse-swap":    eventNameList,
+		},
+	},
+	"ws": {
+		Name: "ws",
+		File: "ext/ws.js",
+		Attributes: map[string]ValueGrammar{
+			"ws-connect": URLValue,
+			"ws-send":    nil,
+		},
+	},
+}
+
+// enabledExtensions holds the names of the extensions whose attributes are
+// accepted by the attribute checks.
+var enabledExtensions = struct {
+	sync.RWMutex
+	names map[string]bool
+}{names: make(map[string]bool)}
+
+// EnableExtensions makes the attributes of the named extensions valid for
+// CheckAttributes and Render. Until an extension is enabled, its attributes,
+// e.g. sse-connect, are reported as invalid. The setting applies to every
+// page, so call it once at startup with the extensions your pages use, e.g.
+//
+//	if err := gohtx.EnableExtensions("sse", "response-targets"); err != nil {
+//		log.Fatal(err)
+//	}
+//
+// Loading an extension's script with Assets.ExtensionScripts or
+// HeadOptions.Extensions doesn't enable it. EnableExtensions returns an error
+// naming any extension not in Extensions.
+func EnableExtensions(names ...string) error {
+	for _, name := range names {
+		if _, ok := Extensions[name]; !ok {
+			return fmt.Errorf("unknown htmx extension %q", name)
+		}
+	}
+	enabledExtensions.Lock()
+	defer enabledExtensions.Unlock()
+	for _, name := range names {
+		enabledExtensions.names[name] = true
+	}
+	return nil
+}
+
+// DisableExtensions reverses EnableExtensions.
+func DisableExtensions(names ...string) {
+	enabledExtensions.Lock()
+	defer enabledExtensions.Unlock()
+	for _, name := range names {
+		delete(enabledExtensions.names, name)
+	}
+}
+
+// extensionAttribute reports whether a is an attribute of an enabled
+// extension and returns the grammar of its value.
+func extensionAttribute(a string) (grammar ValueGrammar, found bool) {
+	enabledExtensions.RLock()
+	defer enabledExtensions.RUnlock()
+	for name := range enabledExtensions.names {
+		ext := Extensions[name]
+		if grammar, found = ext.Attributes[a]; found {
+			return
+		}
+		if ext.Match != nil {
+			if grammar, found = ext.Match(a); found {
+				return
+			}
+		}
+	}
+	return
+}
+
+// ExtensionScripts returns the script elements that load the named
+// extensions from a. Include it in the head after the htmx script, e.g.
+//
+//	scripts, err := assets.ExtensionScripts("sse", "loading-states")
+//	...
+//	Head(``, assets.HeadContent(true, false, true), scripts)
+//
+// Enable the extensions' attributes with EnableExtensions.
+func (a *Assets) ExtensionScripts(names ...string) (*HtmlTree, error) {
+	files, err := extensionFiles(names)
+	if err != nil {
+		return nil, err
+	}
+	var scripts []interface{}
+	for _, file := range files {
+		scripts = append(scripts, Script(``, a.attrs("src", file)))
+	}
+	return Null(scripts...), nil
+}
+
+// extensionFiles returns the paths of the scripts of the named extensions,
+// sorted by name so the output doesn't depend on the order of names.
+func extensionFiles(names []string) ([]string, error) {
+	for _, name := range names {
+		if _, ok := Extensions[name]; !ok {
+			return nil, fmt.Errorf("unknown htmx extension %q", name)
+		}
+	}
+	names = append([]string{}, names...)
+	sort.Strings(names)
+	files := make([]string, len(names))
+	for i, name := range names {
+		files[i] = Extensions[name].File
+	}
+	return files, nil
+}
+
+// responseTargetName matches the attributes of the response-targets
+// extension, e.g. hx-target-404, hx-target-40*, hx-target-5xx, hx-target-*
+// and hx-target-error.
+var responseTargetName = regexp.MustCompile(`^hx-target-([1-5][0-9][0-9*x]|[1-5][*x]{1,2}|[*x]{1,3}|error)$`)
+
+// matchResponseTarget recognizes the attributes of the response-targets
+// extension. Their values follow the grammar of hx-target.
+func matchResponseTarget(name string) (ValueGrammar, bool) {
+	if !responseTargetName.MatchString(name) {
+		return nil, false
+	}
+	return hxTarget, true
+}
+
+// eventNameList accepts a comma-separated list of event names, e.g. the
+// value of sse-swap.
+func eventNameList(name, value string) error {
+	for _, event := range strings.Split(value, ",") {
+		event = strings.TrimSpace(event)
+		if event == "" || strings.ContainsAny(event, " \t\n") {
+			return fmt.Errorf("must be a comma-separated list of event names")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package gohtx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestExtensionFiles(t *testing.T) {
+	for name, ext := range Extensions {
+		if ext.Name != name {
+			t.Errorf("extension %s is listed as %s", ext.Name, name)
+		}
+		a, ok := assets[ext.File]
+		if !ok || !bytes.Contains(a.data, []byte(`htmx.defineExtension("`+name+`"`)) {
+			t.Errorf("%s: %s is missing or doesn't define the extension", name, ext.File)
+		}
+	}
+}
+
+func TestExtensionAttributes(t *testing.T) {
+	tree := Div(`hx-ext="sse,response-targets" sse-connect="/events" hx-target-404="#missing" hx-target-5xx="this"`,
+		Div(`sse-swap="progress, done"`, "waiting"),
+	)
+	check := func() (errs []error) {
+		var perrs []AttributeErrors
+		tree.CheckAttributes(&perrs)
+		for _, e := range perrs {
+			errs = append(errs, e.Errs...)
+		}
+		return
+	}
+	if errs := check(); len(errs) != 4 {
+		t.Errorf("expected 4 errors before enabling the extensions, got %v", errs)
+	}
+	if err := EnableExtensions("sse", "response-targets"); err != nil {
+		t.Fatal(err)
+	}
+	defer DisableExtensions("sse", "response-targets")
+	if errs := check(); len(errs) != 0 {
+		t.Errorf("unexpected errors after enabling the extensions: %v", errs)
+	}
+
+	for _, test := range []struct {
+		tag, attrs string
+		valid      bool
+	}{
+		{"div", `hx-target-40x="#a"`, true},
+		{"div", `hx-target-4*="#a"`, true},
+		{"div", `hx-target-4xx="#a"`, true},
+		{"div", `hx-target-*="#a"`, true},
+		{"div", `hx-target-error="#a"`, true},
+		{"div", `data-hx-target-404="#a"`, true},
+		{"div", `hx-target-4="#a"`, false},
+		{"div", `hx-target-4040="#a"`, false},
+		{"div", `hx-target-404="closest"`, false},
+		{"div", `sse-swap="a b"`, false},
+		{"div", `ws-connect="/chat"`, false}, // ws isn't enabled
+	} {
+		errs, err := checkTagAttributes(test.tag, test.attrs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if valid := len(errs) == 0; valid != test.valid {
+			t.Errorf("%s: expected valid=%v, got %v", test.attrs, test.valid, errs)
+		}
+	}
+	if err := EnableExtensions("nosuch"); err == nil {
+		t.Errorf("expected an error enabling an unknown extension")
+	}
+}
+
+func TestPreloadExtension(t *testing.T) {
+	link := A(`href="/next"`, "Next").With(Attr("preload", "mouseover"))
+	var buf bytes.Buffer
+	if err := Render(link, &buf, -1); err == nil {
+		t.Errorf("expected preload on <a> to be invalid before enabling the extension")
+	}
+	if err := EnableExtensions("preload"); err != nil {
+		t.Fatal(err)
+	}
+	defer DisableExtensions("preload")
+	buf.Reset()
+	if err := Render(link, &buf, -1); err != nil {
+		t.Errorf("unexpected error with the preload extension enabled: %v", err)
+	}
+	// The html grammar still applies to media elements.
+	if err := checkAttrValue("video", "preload", "mouseover"); err == nil {
+		t.Errorf("expected an error for preload=mouseover on <video>")
+	}
+	if err := checkAttrValue("a", "preload-images", "maybe"); err == nil {
+		t.Errorf("expected an error for preload-images=maybe")
+	}
+}
+
+func TestExtensionScripts(t *testing.T) {
+	scripts, err := NewAssets("/static/").ExtensionScripts("ws", "json-enc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := Render(scripts, &buf, -1); err != nil {
+		t.Fatal(err)
+	}
+	s := buf.String()
+	json, ws := strings.Index(s, "/static/ext/json-enc."), strings.Index(s, "/static/ext/ws.")
+	if json < 0 || ws < json || strings.Count(s, `integrity="sha384-`) != 2 {
+		t.Errorf("unexpected scripts %s", s)
+	}
+	if errs, _ := checkTagAttributes("div", `ws-connect="/chat" ws-send`); len(errs) != 2 {
+		t.Errorf("loading the ws script shouldn't enable its attributes: %v", errs)
+	}
+	if _, err := DefaultAssets.ExtensionScripts("sse", "nosuch"); err == nil {
+		t.Errorf("expected an error for an unknown extension")
+	}
+}
@@ -1,19 +1,31 @@
 package gohtx
 
 import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"crypto/sha512"
 	"embed"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
 	"log"
 	"net/http"
 	"path"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 const GohtxAssetPath = "/gohtx/" // default route to embedded assets.
 
-//go:embed htmx.min.js hyperscript.js bulma/*
+//go:embed htmx.min.js hyperscript.js bulma/* ext/*.js
 var staticFiles embed.FS
 
-// GohtxFS returns the embedded filesystem containing htmx.min.js and bulma.css.
+// GohtxFS returns the embedded filesystem containing htmx.min.js, the htmx
+// extensions in ext/ and bulma.css.
 // To serve them, do something like:
 // fs := GohtxFS()
 // buf, err := fs.ReadFile("htmx.min.js")
@@ -21,68 +33,361 @@ func GohtxFS() embed.FS {
 	return staticFiles
 }
 
-// DefaultHeadContent calls CustomHeadContent specifying htmx and bulma
-// without hyperscript.
-func DefaultHeadContent() (h *HtmlTree) {
-	h = CustomHeadContent(true, false, true)
+// asset is an embedded file with the validators needed to serve it with
+// caching headers.
+type asset struct {
+	data        []byte
+	etag        string // a strong ETag derived from the content hash
+	fingerprint string // the path with a short content hash, e.g. htmx.min.0123456789ab.js
+	integrity   string // the Subresource Integrity hash, e.g. sha384-...
+	gzipped     []byte // data compressed with gzip, nil if it doesn't compress
+}
+
+// AssetTypes maps the extensions of the assets that may be served to their
+// Content-Types. Requests for other files in the embedded FS, like the
+// Bulma LICENSE, get 404 Not Found. Add entries before serving if you embed
+// other kinds of files.
+var AssetTypes = map[string]string{
+	".css":   "text/css; charset=utf-8",
+	".js":    "text/javascript; charset=utf-8",
+	".map":   "application/json",
+	".svg":   "image/svg+xml",
+	".woff":  "font/woff",
+	".woff2": "font/woff2",
+	".ttf":   "font/ttf",
+	".png":   "image/png",
+	".ico":   "image/x-icon",
+	".txt":   "text/plain; charset=utf-8",
+}
+
+// compressible lists the extensions of text assets worth compressing.
+var compressible = []string{".css", ".js", ".map", ".svg", ".html", ".txt"}
+
+// assets indexes the embedded files by path and by fingerprinted path. It's
+// built once, at init, so requests don't read the FS or hash the content.
+// AddAsset adds generated files to it, under assetsMu.
+var assets map[string]*asset
+
+// assetsMu guards assets once init is done.
+var assetsMu sync.RWMutex
+
+// assetModTime is the Last-Modified time of all assets. The embedded files
+// have no modification times, so the time the program started is used.
+var assetModTime = time.Now().UTC().Truncate(time.Second)
+
+func init() {
+	var err error
+	assets, err = indexAssets(staticFiles)
+	if err != nil {
+		// The FS is compiled in, so failure is a programming error.
+		panic(err)
+	}
+}
+
+// indexAssets reads and hashes every file in fsys except hidden ones, like
+// .DS_Store, whose names begin with a dot. Each asset is indexed under its
+// path and its fingerprinted path.
+func indexAssets(fsys fs.FS) (index map[string]*asset, err error) {
+	index = make(map[string]*asset)
+	err = fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if strings.HasPrefix(d.Name(), ".") {
+			return nil
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		a, err := newAsset(name, data)
+		if err != nil {
+			return err
+		}
+		index[name] = a
+		index[a.fingerprint] = a
+		return nil
+	})
 	return
 }
 
-// CustomHeadContent returns the same Meta elements you would typically include
-// in the <head> element of a responsive web page and allows you to choose
-// which, if any, of htmx, hyperscript, and bulma to include.
-func CustomHeadContent(htmx, hyperscript, bulma bool) (h *HtmlTree) {
-	var options []interface{}
-	options = append(options,
-		Meta(`charset="utf-8"`),
-		Meta(`name="viewport" content="width=device-width, initial-scale=1"`),
-	)
+// newAsset returns the asset named name with the given content, hashed and,
+// if it's text, compressed.
+func newAsset(name string, data []byte) (a *asset, err error) {
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	sri := sha512.Sum384(data)
+	a = &asset{
+		data:        data,
+		etag:        `"` + hash[:32] + `"`,
+		fingerprint: fingerprint(name, hash[:12]),
+		integrity:   "sha384-" + base64.StdEncoding.EncodeToString(sri[:]),
+	}
+	if stringInSlice(path.Ext(name), compressible) {
+		a.gzipped, err = gzipBytes(data)
+	}
+	return
+}
 
-	if htmx {
-		options = append(options, Script(`src="gohtx/htmx.min.js"`))
+// AddAsset adds a generated file, like a stylesheet made by PurgeBulma, to
+// the assets served by every Assets. It's served, fingerprinted and
+// referenced from head content exactly like an embedded file, e.g.
+//
+//	AddAsset("app.css", []byte(PurgeBulma(pages)))
+//	BuildHeadContent(HeadOptions{Stylesheets: []string{"app.css"}})
+//
+// Adding a name again replaces its content. The old fingerprinted path is
+// still served, so pages that link to it keep working. AddAsset returns an
+// error if name is embedded, isn't a clean relative path or has an extension
+// that isn't in AssetTypes.
+func AddAsset(name string, data []byte) error {
+	if _, ok := AssetTypes[path.Ext(name)]; !ok {
+		return fmt.Errorf("can't add asset %s: %s isn't in AssetTypes", name, path.Ext(name))
+	}
+	if path.Clean(name) != name || strings.HasPrefix(name, "/") || strings.HasPrefix(name, "../") {
+		return fmt.Errorf("can't add asset %s: not a clean relative path", name)
 	}
-	if hyperscript {
-		options = append(options, Script(`src="gohtx/hyperscript.js"`))
+	if _, err := fs.Stat(staticFiles, name); err == nil {
+		return fmt.Errorf("can't add asset %s: it's embedded", name)
 	}
-	if bulma {
-		options = append(options,
-			Link(`rel="stylesheet" href="gohtx/bulma/css/bulma.min.css" type="text/css"`))
+	a, err := newAsset(name, data)
+	if err != nil {
+		return err
 	}
+	assetsMu.Lock()
+	defer assetsMu.Unlock()
+	assets[name] = a
+	assets[a.fingerprint] = a
+	return nil
+}
 
-	h = Null(options...)
+// lookupAsset returns the asset with the given path or fingerprinted path.
+func lookupAsset(name string) (a *asset, found bool) {
+	assetsMu.RLock()
+	defer assetsMu.RUnlock()
+	a, found = assets[name]
 	return
 }
 
-// AddGohtxAssetHandler is a convenience function that adds an http.Handler to
-// process requests for assets in the embedded FS. Use this to avoid having to
-// know about the path string.
-func AddGohtxAssetHandler() {
-	http.Handle(GohtxAssetPath, http.HandlerFunc(assetHandler))
+// fingerprint inserts hash into name before the extension, e.g.
+// fingerprint("bulma/css/bulma.min.css", "0123") returns
+// "bulma/css/bulma.min.0123.css".
+func fingerprint(name, hash string) string {
+	ext := path.Ext(name)
+	return strings.TrimSuffix(name, ext) + "." + hash + ext
 }
 
-// assetHandler is the unexported handler installed by GohtxAssetHandler
-func assetHandler(w http.ResponseWriter, r *http.Request) {
-	asset := strings.TrimPrefix(r.URL.Path, GohtxAssetPath)
-	log.Printf("%s requested", asset)
-	fs := GohtxFS()
-	buf, err := fs.ReadFile(asset)
+// gzipBytes returns data compressed with gzip at the best compression level,
+// or nil if that doesn't make it smaller.
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
 	if err != nil {
-		log.Printf("%v", err)
-		w.WriteHeader(http.StatusInternalServerError)
+		return nil, err
+	}
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	if buf.Len() >= len(data) {
+		return nil, nil
+	}
+	return buf.Bytes(), nil
+}
+
+// acceptsEncoding reports whether the Accept-Encoding header of r accepts
+// coding, either by name or through "*", with a non-zero quality.
+func acceptsEncoding(r *http.Request, coding string) bool {
+	accepted := false
+	for _, field := range strings.Split(strings.Join(r.Header.Values("Accept-Encoding"), ","), ",") {
+		params := strings.Split(field, ";")
+		name := strings.ToLower(strings.TrimSpace(params[0]))
+		if name != coding && name != "*" {
+			continue
+		}
+		q := 1.0
+		for _, p := range params[1:] {
+			p = strings.TrimSpace(p)
+			if strings.HasPrefix(p, "q=") {
+				if v, err := strconv.ParseFloat(p[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if name == coding {
+			// An explicit entry overrides "*".
+			return q > 0
+		}
+		accepted = q > 0
+	}
+	return accepted
+}
+
+// Assets serves the embedded files under a URL path prefix and builds the
+// head content that loads them from there. Mount it on any mux, at any path,
+// e.g.
+//
+//	assets := NewAssets("/static/gohtx/")
+//	assets.Register(mux)
+//	...
+//	Head(``, assets.HeadContent(true, false, true), Title(``, "Admin"))
+//
+// The URLs in the head content are absolute, so they work from pages at any
+// depth, e.g. /admin/users.
+type Assets struct {
+	prefix string // the URL path the assets are served under, with leading and trailing slashes
+
+	// Log, if not nil, logs each request with its response status.
+	Log *log.Logger
+}
+
+// DefaultAssets serves the assets under GohtxAssetPath. It's used by
+// AddGohtxAssetHandler and CustomHeadContent.
+var DefaultAssets = NewAssets(GohtxAssetPath)
+
+// NewAssets returns an Assets that serves the embedded files under prefix,
+// e.g. "/static/gohtx/". Leading and trailing slashes are added to prefix if
+// they're missing.
+func NewAssets(prefix string) *Assets {
+	prefix = "/" + strings.Trim(prefix, "/") + "/"
+	if prefix == "//" {
+		prefix = "/"
+	}
+	return &Assets{prefix: prefix}
+}
+
+// Prefix returns the URL path the assets are served under.
+func (a *Assets) Prefix() string {
+	return a.prefix
+}
+
+// Handler returns a.
+func (a *Assets) Handler() http.Handler {
+	return a
+}
+
+// Register adds a's handler to mux at a's prefix. Mux may be an
+// *http.ServeMux or any router with the same Handle method, like chi's.
+func (a *Assets) Register(mux interface {
+	Handle(pattern string, handler http.Handler)
+}) {
+	mux.Handle(a.prefix, a)
+}
+
+// URL returns the absolute, fingerprinted URL of the named asset, e.g.
+// URL("htmx.min.js") returns "/gohtx/htmx.min.0123456789ab.js". The
+// fingerprint changes whenever the content does, so the URL can be cached
+// indefinitely. Names that aren't embedded are returned unfingerprinted.
+func (a *Assets) URL(name string) string {
+	if asset, ok := lookupAsset(name); ok {
+		name = asset.fingerprint
+	}
+	return a.prefix + name
+}
+
+// attrs returns the attributes that load the named asset from attr, i.e. src
+// or href, with its integrity hash.
+func (a *Assets) attrs(attr, name string) Attrs {
+	attrs := Attrs{attr: a.URL(name)}
+	if asset, ok := lookupAsset(name); ok {
+		attrs["integrity"] = asset.integrity
+		attrs["crossorigin"] = "anonymous"
+	}
+	return attrs
+}
+
+// HeadContent returns the same Meta elements you would typically include in
+// the <head> element of a responsive web page and allows you to choose
+// which, if any, of htmx, hyperscript, and bulma to include from a. Use
+// BuildHeadContent for anything more.
+func (a *Assets) HeadContent(htmx, hyperscript, bulma bool) (h *HtmlTree) {
+	// Without extensions, BuildHeadContent can't fail.
+	h, _ = a.BuildHeadContent(HeadOptions{Htmx: htmx, Hyperscript: hyperscript, Bulma: bulma})
+	return
+}
+
+// DefaultHeadContent calls CustomHeadContent specifying htmx and bulma
+// without hyperscript.
+func DefaultHeadContent() (h *HtmlTree) {
+	h = CustomHeadContent(true, false, true)
+	return
+}
+
+// CustomHeadContent returns the head content of DefaultAssets. It allows you
+// to choose which, if any, of htmx, hyperscript, and bulma to include. See
+// BuildHeadContent for titles, descriptions and the like.
+func CustomHeadContent(htmx, hyperscript, bulma bool) (h *HtmlTree) {
+	h = DefaultAssets.HeadContent(htmx, hyperscript, bulma)
+	return
+}
+
+// AddGohtxAssetHandler is a convenience function that registers DefaultAssets
+// with http.DefaultServeMux. Use this to avoid having to know about the path
+// string.
+func AddGohtxAssetHandler() {
+	DefaultAssets.Register(http.DefaultServeMux)
+}
+
+// ServeHTTP serves the asset named by the request path after a's prefix. The
+// prefix is optional, so a also works behind http.StripPrefix. Assets are
+// served from the index with an ETag and Last-Modified time, so browsers can
+// revalidate them cheaply, and conditional, HEAD and Range requests are left
+// to http.ServeContent. Requests for fingerprinted paths are cached as
+// immutable for a year. Text assets are sent gzipped, from a copy compressed
+// at init, to clients that accept it. Requests for directories, hidden files,
+// files whose extensions aren't in AssetTypes and files that don't exist get
+// 404 Not Found.
+func (a *Assets) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if a.Log != nil {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		defer func() {
+			a.Log.Printf("%s %s %d", r.Method, r.URL.Path, rec.status)
+		}()
+		w = rec
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
 		return
 	}
-	// Write the Content-Type to the header
-	ext := path.Ext(asset)
-	switch ext {
-	case ".css":
-		w.Header().Set("Content-Type", "text/css")
-	case ".js":
-		w.Header().Set("Content-Type", "text/javascript")
-	default:
-		w.Header().Set("Content-Type", http.DetectContentType(buf))
-	}
-	_, err = w.Write(buf)
-	if err != nil {
-		log.Println(err)
+	name := strings.TrimPrefix(r.URL.Path, a.prefix)
+	name = strings.TrimPrefix(name, "/")
+	contentType, servable := AssetTypes[path.Ext(name)]
+	asset, found := lookupAsset(name)
+	if !servable || !found || path.Clean(name) != name {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	if name == asset.fingerprint {
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+	content, etag := asset.data, asset.etag
+	if asset.gzipped != nil {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if acceptsEncoding(r, "gzip") {
+			// Each encoding is a different representation and needs its
+			// own ETag.
+			content, etag = asset.gzipped, strings.TrimSuffix(asset.etag, `"`)+`-gzip"`
+			w.Header().Set("Content-Encoding", "gzip")
+		}
 	}
+	w.Header().Set("ETag", etag)
+	http.ServeContent(w, r, name, assetModTime, bytes.NewReader(content))
+}
+
+// statusRecorder records the status written to a ResponseWriter.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader records status and passes it on.
+func (rec *statusRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
 }
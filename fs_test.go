@@ -2,6 +2,15 @@ package gohtx
 
 import (
 	"bytes"
+	"compress/gzip"
+	"crypto/sha512"
+	"encoding/base64"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
 	"strings"
 	"testing"
 )
@@ -47,13 +56,13 @@ func TestCustomHeadContent(t *testing.T) {
 		t.Error(err)
 	}
 	s := buf.String()
-	if strings.Contains(s, "htmx.min.js") {
+	if strings.Contains(s, DefaultAssets.URL("htmx.min.js")) {
 		t.Errorf("unexpected %s in \n%s\n", "htmx", s)
 	}
-	if strings.Contains(s, "hyperscript.js") {
+	if strings.Contains(s, DefaultAssets.URL("hyperscript.js")) {
 		t.Errorf("unexpected %s in \n%s\n", "hyperscript.js", s)
 	}
-	if strings.Contains(s, "bulma/css/bulma.min.css") {
+	if strings.Contains(s, DefaultAssets.URL("bulma/css/bulma.min.css")) {
 		t.Errorf("unexpected %s in \n%s\n", "bulma/css/bulma.min.css", s)
 	}
 
@@ -63,13 +72,13 @@ func TestCustomHeadContent(t *testing.T) {
 		t.Error(err)
 	}
 	s = buf.String()
-	if strings.Contains(s, "htmx.min.js") {
+	if strings.Contains(s, DefaultAssets.URL("htmx.min.js")) {
 		t.Errorf("unexpected %s in \n%s\n", "htmx", s)
 	}
-	if strings.Contains(s, "hyperscript.js") {
+	if strings.Contains(s, DefaultAssets.URL("hyperscript.js")) {
 		t.Errorf("unexpected %s in \n%s\n", "hyperscript.js", s)
 	}
-	if !strings.Contains(s, "bulma/css/bulma.min.css") {
+	if !strings.Contains(s, DefaultAssets.URL("bulma/css/bulma.min.css")) {
 		t.Errorf("expected %s in \n%s\n", "bulma/css/bulma.min.css", s)
 	}
 
@@ -79,13 +88,13 @@ func TestCustomHeadContent(t *testing.T) {
 		t.Error(err)
 	}
 	s = buf.String()
-	if strings.Contains(s, "htmx.min.js") {
+	if strings.Contains(s, DefaultAssets.URL("htmx.min.js")) {
 		t.Errorf("unexpected %s in \n%s\n", "htmx", s)
 	}
-	if !strings.Contains(s, "hyperscript.js") {
+	if !strings.Contains(s, DefaultAssets.URL("hyperscript.js")) {
 		t.Errorf("expected %s in \n%s\n", "hyperscript.js", s)
 	}
-	if strings.Contains(s, "bulma/css/bulma.min.css") {
+	if strings.Contains(s, DefaultAssets.URL("bulma/css/bulma.min.css")) {
 		t.Errorf("unexpected %s in \n%s\n", "bulma/css/bulma.min.css", s)
 	}
 
@@ -95,13 +104,13 @@ func TestCustomHeadContent(t *testing.T) {
 		t.Error(err)
 	}
 	s = buf.String()
-	if strings.Contains(s, "htmx.min.js") {
+	if strings.Contains(s, DefaultAssets.URL("htmx.min.js")) {
 		t.Errorf("unexpected %s in \n%s\n", "htmx", s)
 	}
-	if !strings.Contains(s, "hyperscript.js") {
+	if !strings.Contains(s, DefaultAssets.URL("hyperscript.js")) {
 		t.Errorf("expected %s in \n%s\n", "hyperscript.js", s)
 	}
-	if !strings.Contains(s, "bulma/css/bulma.min.css") {
+	if !strings.Contains(s, DefaultAssets.URL("bulma/css/bulma.min.css")) {
 		t.Errorf("expected %s in \n%s\n", "bulma/css/bulma.min.css", s)
 	}
 
@@ -111,13 +120,13 @@ func TestCustomHeadContent(t *testing.T) {
 		t.Error(err)
 	}
 	s = buf.String()
-	if !strings.Contains(s, "htmx.min.js") {
+	if !strings.Contains(s, DefaultAssets.URL("htmx.min.js")) {
 		t.Errorf("expected %s in \n%s\n", "htmx", s)
 	}
-	if strings.Contains(s, "hyperscript.js") {
+	if strings.Contains(s, DefaultAssets.URL("hyperscript.js")) {
 		t.Errorf("unexpected %s in \n%s\n", "hyperscript.js", s)
 	}
-	if strings.Contains(s, "bulma/css/bulma.min.css") {
+	if strings.Contains(s, DefaultAssets.URL("bulma/css/bulma.min.css")) {
 		t.Errorf("unexpected %s in \n%s\n", "bulma/css/bulma.min.css", s)
 	}
 
@@ -127,13 +136,13 @@ func TestCustomHeadContent(t *testing.T) {
 		t.Error(err)
 	}
 	s = buf.String()
-	if !strings.Contains(s, "htmx.min.js") {
+	if !strings.Contains(s, DefaultAssets.URL("htmx.min.js")) {
 		t.Errorf("expected %s in \n%s\n", "htmx", s)
 	}
-	if strings.Contains(s, "hyperscript.js") {
+	if strings.Contains(s, DefaultAssets.URL("hyperscript.js")) {
 		t.Errorf("unexpected %s in \n%s\n", "hyperscript.js", s)
 	}
-	if !strings.Contains(s, "bulma/css/bulma.min.css") {
+	if !strings.Contains(s, DefaultAssets.URL("bulma/css/bulma.min.css")) {
 		t.Errorf("expected %s in \n%s\n", "bulma/css/bulma.min.css", s)
 	}
 
@@ -143,13 +152,13 @@ func TestCustomHeadContent(t *testing.T) {
 		t.Error(err)
 	}
 	s = buf.String()
-	if !strings.Contains(s, "htmx.min.js") {
+	if !strings.Contains(s, DefaultAssets.URL("htmx.min.js")) {
 		t.Errorf("expected %s in \n%s\n", "htmx", s)
 	}
-	if !strings.Contains(s, "hyperscript.js") {
+	if !strings.Contains(s, DefaultAssets.URL("hyperscript.js")) {
 		t.Errorf("expected %s in \n%s\n", "hyperscript.js", s)
 	}
-	if strings.Contains(s, "bulma/css/bulma.min.css") {
+	if strings.Contains(s, DefaultAssets.URL("bulma/css/bulma.min.css")) {
 		t.Errorf("unexpected %s in \n%s\n", "bulma/css/bulma.min.css", s)
 	}
 
@@ -159,14 +168,221 @@ func TestCustomHeadContent(t *testing.T) {
 		t.Error(err)
 	}
 	s = buf.String()
-	if !strings.Contains(s, "htmx.min.js") {
+	if !strings.Contains(s, DefaultAssets.URL("htmx.min.js")) {
 		t.Errorf("expected %s in \n%s\n", "htmx", s)
 	}
-	if !strings.Contains(s, "hyperscript.js") {
+	if !strings.Contains(s, DefaultAssets.URL("hyperscript.js")) {
 		t.Errorf("expected %s in \n%s\n", "hyperscript.js", s)
 	}
-	if !strings.Contains(s, "bulma/css/bulma.min.css") {
+	if !strings.Contains(s, DefaultAssets.URL("bulma/css/bulma.min.css")) {
 		t.Errorf("expected %s in \n%s\n", "bulma/css/bulma.min.css", s)
 	}
 
 }
+
+func TestAssetHandler(t *testing.T) {
+	a := assets["htmx.min.js"]
+	get := func(method, url string, header map[string]string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, url, nil)
+		for k, v := range header {
+			r.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		DefaultAssets.ServeHTTP(w, r)
+		return w
+	}
+
+	w := get("GET", "/gohtx/htmx.min.js", nil)
+	if w.Code != http.StatusOK || !bytes.Equal(w.Body.Bytes(), a.data) {
+		t.Fatalf("GET: status %d, %d bytes", w.Code, w.Body.Len())
+	}
+	if w.Header().Get("ETag") != a.etag || w.Header().Get("Last-Modified") == "" {
+		t.Errorf("GET: missing validators in %v", w.Header())
+	}
+	if w.Header().Get("Cache-Control") != "no-cache" {
+		t.Errorf("GET: unversioned URL cached as %q", w.Header().Get("Cache-Control"))
+	}
+	if w.Header().Get("Content-Type") != "text/javascript; charset=utf-8" {
+		t.Errorf("GET: Content-Type %q", w.Header().Get("Content-Type"))
+	}
+
+	w = get("GET", "/gohtx/"+a.fingerprint, nil)
+	if cc := w.Header().Get("Cache-Control"); !strings.Contains(cc, "immutable") {
+		t.Errorf("versioned GET: Cache-Control %q", cc)
+	}
+
+	w = get("GET", "/gohtx/htmx.min.js", map[string]string{"If-None-Match": a.etag})
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("conditional GET: status %d, %d bytes", w.Code, w.Body.Len())
+	}
+
+	w = get("HEAD", "/gohtx/htmx.min.js", nil)
+	if w.Code != http.StatusOK || w.Body.Len() != 0 || w.Header().Get("Content-Length") != strconv.Itoa(len(a.data)) {
+		t.Errorf("HEAD: status %d, %d bytes, headers %v", w.Code, w.Body.Len(), w.Header())
+	}
+
+	w = get("GET", "/gohtx/htmx.min.js", map[string]string{"Range": "bytes=0-9"})
+	if w.Code != http.StatusPartialContent || !bytes.Equal(w.Body.Bytes(), a.data[:10]) {
+		t.Errorf("Range: status %d, body %q", w.Code, w.Body.String())
+	}
+}
+
+func TestAssetGzip(t *testing.T) {
+	for _, name := range []string{"htmx.min.js", "hyperscript.js", "bulma/css/bulma.min.css", "bulma/css/bulma.css"} {
+		a := assets[name]
+		if a.gzipped == nil {
+			t.Errorf("%s: no gzip variant", name)
+			continue
+		}
+		r := httptest.NewRequest("GET", "/gohtx/"+name, nil)
+		r.Header.Set("Accept-Encoding", "br;q=1.0, gzip;q=0.8")
+		w := httptest.NewRecorder()
+		DefaultAssets.ServeHTTP(w, r)
+		if w.Header().Get("Content-Encoding") != "gzip" || w.Header().Get("Vary") != "Accept-Encoding" {
+			t.Errorf("%s: headers %v", name, w.Header())
+		}
+		if w.Header().Get("ETag") == a.etag {
+			t.Errorf("%s: gzip variant has the ETag of the identity encoding", name)
+		}
+		zr, err := gzip.NewReader(w.Body)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		data, err := ioutil.ReadAll(zr)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !bytes.Equal(data, a.data) {
+			t.Errorf("%s: decompressed content differs from the embedded file", name)
+		}
+	}
+
+	for _, accept := range []string{"", "identity", "gzip;q=0", "*;q=0", "br, *;q=0, gzip;q=0"} {
+		r := httptest.NewRequest("GET", "/gohtx/htmx.min.js", nil)
+		r.Header.Set("Accept-Encoding", accept)
+		w := httptest.NewRecorder()
+		DefaultAssets.ServeHTTP(w, r)
+		if w.Header().Get("Content-Encoding") != "" || !bytes.Equal(w.Body.Bytes(), assets["htmx.min.js"].data) {
+			t.Errorf("Accept-Encoding %q: expected identity encoding, got %v", accept, w.Header())
+		}
+		if w.Header().Get("Vary") != "Accept-Encoding" {
+			t.Errorf("Accept-Encoding %q: missing Vary", accept)
+		}
+	}
+	for _, accept := range []string{"gzip", "GZIP", "deflate, gzip", "*", "*;q=0.5"} {
+		r := httptest.NewRequest("GET", "/gohtx/htmx.min.js", nil)
+		r.Header.Set("Accept-Encoding", accept)
+		if !acceptsEncoding(r, "gzip") {
+			t.Errorf("Accept-Encoding %q should accept gzip", accept)
+		}
+	}
+}
+
+func TestAssetIntegrity(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Render(CustomHeadContent(true, false, true), &buf, -1); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"htmx.min.js", "bulma/css/bulma.min.css"} {
+		data, err := GohtxFS().ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sum := sha512.Sum384(data)
+		integrity := "sha384-" + base64.StdEncoding.EncodeToString(sum[:])
+		url := DefaultAssets.URL(name)
+		if !regexp.MustCompile(`^/gohtx/.*\.[0-9a-f]{12}\.(js|css)$`).MatchString(url) {
+			t.Errorf("%s: unexpected URL %s", name, url)
+		}
+		for _, want := range []string{`"` + url + `"`, `integrity="` + integrity + `"`, `crossorigin="anonymous"`} {
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("%s: expected %s in %s", name, want, buf.String())
+			}
+		}
+		if assets[strings.TrimPrefix(url, "/gohtx/")] != assets[name] {
+			t.Errorf("%s: fingerprinted URL %s doesn't resolve", name, url)
+		}
+	}
+}
+
+func TestAssetsPrefix(t *testing.T) {
+	for prefix, expect := range map[string]string{
+		"/static/gohtx/": "/static/gohtx/",
+		"static/gohtx":   "/static/gohtx/",
+		"":               "/",
+	} {
+		if got := NewAssets(prefix).Prefix(); got != expect {
+			t.Errorf("NewAssets(%q): expected prefix %q, got %q", prefix, expect, got)
+		}
+	}
+
+	a := NewAssets("static/gohtx")
+	mux := http.NewServeMux()
+	a.Register(mux)
+	var buf bytes.Buffer
+	if err := Render(a.HeadContent(true, true, true), &buf, -1); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"htmx.min.js", "hyperscript.js", "bulma/css/bulma.min.css"} {
+		url := a.URL(name)
+		if !strings.HasPrefix(url, "/static/gohtx/") || !strings.Contains(buf.String(), `"`+url+`"`) {
+			t.Errorf("%s: expected absolute URL %s in %s", name, url, buf.String())
+		}
+		// Resolve the URL as a browser would from a page deep in the site.
+		r := httptest.NewRequest("GET", "/admin/users", nil)
+		ref, err := r.URL.Parse(url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", ref.String(), nil))
+		if w.Code != http.StatusOK || !bytes.Equal(w.Body.Bytes(), assets[name].data) {
+			t.Errorf("%s: status %d from %s", name, w.Code, ref)
+		}
+	}
+
+	// Assets also work behind http.StripPrefix.
+	h := http.StripPrefix("/static/gohtx", a.Handler())
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/static/gohtx/htmx.min.js", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("behind StripPrefix: status %d", w.Code)
+	}
+}
+
+func TestAssetsNotFound(t *testing.T) {
+	var logged bytes.Buffer
+	a := NewAssets("/gohtx/")
+	a.Log = log.New(&logged, "", 0)
+	for _, test := range []struct {
+		method, path string
+		status       int
+		contentType  string
+	}{
+		{"GET", "/gohtx/htmx.min.js", http.StatusOK, "text/javascript; charset=utf-8"},
+		{"GET", "/gohtx/bulma/css/bulma.css.map", http.StatusOK, "application/json"},
+		{"GET", "/gohtx/nosuch.js", http.StatusNotFound, ""},
+		{"GET", "/gohtx/bulma/.DS_Store", http.StatusNotFound, ""},
+		{"GET", "/gohtx/bulma/LICENSE", http.StatusNotFound, ""},
+		{"GET", "/gohtx/bulma/", http.StatusNotFound, ""},
+		{"GET", "/gohtx/bulma/css", http.StatusNotFound, ""},
+		{"GET", "/gohtx/", http.StatusNotFound, ""},
+		{"GET", "/gohtx/bulma/../htmx.min.js", http.StatusNotFound, ""},
+		{"POST", "/gohtx/htmx.min.js", http.StatusMethodNotAllowed, ""},
+	} {
+		w := httptest.NewRecorder()
+		a.ServeHTTP(w, httptest.NewRequest(test.method, test.path, nil))
+		if w.Code != test.status {
+			t.Errorf("%s %s: expected status %d, got %d", test.method, test.path, test.status, w.Code)
+		}
+		if test.contentType != "" && w.Header().Get("Content-Type") != test.contentType {
+			t.Errorf("%s %s: Content-Type %q", test.method, test.path, w.Header().Get("Content-Type"))
+		}
+	}
+	if _, ok := assets["bulma/.DS_Store"]; ok {
+		t.Errorf(".DS_Store is indexed")
+	}
+	if !strings.Contains(logged.String(), "GET /gohtx/nosuch.js 404") {
+		t.Errorf("expected request log, got %q", logged.String())
+	}
+}
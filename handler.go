@@ -0,0 +1,192 @@
+package gohtx
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+)
+
+// Response is the result of a ResponseFunc. Status defaults to 200 OK. Header
+// is added to the response headers and Hx sets htmx response headers. A nil
+// Body sends no content.
+type Response struct {
+	Status int
+	Header http.Header
+	Hx     *HxResponse
+	Body   *HtmlTree
+}
+
+// ResponseFunc is the signature of the functions adapted by ResponseHandler.
+type ResponseFunc func(r *http.Request) (*Response, error)
+
+// HTTPError is an error with the HTTP status to report it with. Errors from
+// adapted handlers that don't wrap an HTTPError, or whose Status isn't a 4xx
+// or 5xx code, are reported as 500 Internal Server Error.
+type HTTPError struct {
+	Status int
+	Err    error
+}
+
+// Error implements error.
+func (e *HTTPError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status))
+	}
+	return fmt.Sprintf("%d %s: %v", e.Status, http.StatusText(e.Status), e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
+
+// Errorf returns an HTTPError with the given status and a message formatted
+// as in fmt.Errorf, e.g. Errorf(http.StatusNotFound, "no item %d", id).
+func Errorf(status int, format string, a ...interface{}) error {
+	return &HTTPError{Status: status, Err: fmt.Errorf(format, a...)}
+}
+
+// ErrorPage returns the content sent when an adapted handler returns an
+// error. Replace it to match the look of your site. The default shows the
+// status, and, for client errors, the error message. The messages of server
+// errors are only logged so internal details aren't leaked. Requests from
+// htmx get a fragment instead of a page. Note that htmx doesn't swap error
+// responses in unless told to in an htmx:beforeSwap event listener.
+var ErrorPage = func(r *http.Request, status int, err error) *HtmlTree {
+	msg := http.StatusText(status)
+	if status < 500 {
+		var herr *HTTPError
+		if errors.As(err, &herr) && herr.Err != nil {
+			msg = herr.Err.Error()
+		}
+	}
+	title := fmt.Sprintf("%d %s", status, http.StatusText(status))
+	if ParseHxRequest(r).WantsFragment() {
+		return Div(`class="notification is-danger"`, msg)
+	}
+	return Null(
+		Raw("<!DOCTYPE html>"),
+		Html(``,
+			Head(``, Meta(`charset="utf-8"`), Title(``, title)),
+			Body(``, H1(``, title), P(``, msg)),
+		),
+	)
+}
+
+// ErrorLog is the logger for errors from adapted handlers and rendering. If
+// nil, errors are logged with the log package's standard logger.
+var ErrorLog *log.Logger
+
+// logf logs a message to ErrorLog.
+func logf(format string, a ...interface{}) {
+	if ErrorLog != nil {
+		ErrorLog.Printf(format, a...)
+		return
+	}
+	log.Printf(format, a...)
+}
+
+// Handler adapts f to an http.Handler. The tree returned by f is rendered as
+// html with status 200. If f returns an error, the ErrorPage is sent with the
+// status of the error.
+func Handler(f func(r *http.Request) (*HtmlTree, error)) http.Handler {
+	return ResponseHandler(func(r *http.Request) (*Response, error) {
+		h, err := f(r)
+		if err != nil {
+			return nil, err
+		}
+		return &Response{Body: h}, nil
+	})
+}
+
+// ResponseHandler adapts f to an http.Handler that sends the status, headers
+// and content of the Response returned by f. If f returns an error, the
+// ErrorPage is sent with the status of the error instead. The content is
+// rendered before anything is sent, so an error found while rendering it,
+// e.g. an invalid attribute, sends the ErrorPage with 500 Internal Server
+// Error rather than a truncated page.
+func ResponseHandler(f ResponseFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp, err := f(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		if resp == nil {
+			resp = &Response{}
+		}
+		header := make(http.Header)
+		if resp.Hx != nil {
+			if err := resp.Hx.SetHeaders(header); err != nil {
+				writeError(w, r, err)
+				return
+			}
+		}
+		var buf *bytes.Buffer
+		if resp.Body != nil {
+			buf = bufferPool.Get().(*bytes.Buffer)
+			defer putBuffer(buf)
+			if err := Render(resp.Body, buf, 0); err != nil {
+				writeError(w, r, fmt.Errorf("rendering response: %w", err))
+				return
+			}
+		}
+		for _, h := range []http.Header{resp.Header, header} {
+			for name, values := range h {
+				for _, v := range values {
+					w.Header().Add(name, v)
+				}
+			}
+		}
+		status := resp.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		if buf == nil {
+			w.WriteHeader(status)
+			return
+		}
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		}
+		w.WriteHeader(status)
+		if _, err := buf.WriteTo(w); err != nil {
+			logf("%s %s: writing response: %v", r.Method, r.URL.Path, err)
+		}
+	})
+}
+
+// bufferPool holds the buffers ResponseHandler renders into.
+var bufferPool = sync.Pool{New: func() interface{} { return new(bytes.Buffer) }}
+
+// maxPooledBuffer is the capacity above which a buffer isn't returned to
+// bufferPool, so one huge page doesn't pin its memory.
+const maxPooledBuffer = 1 << 20
+
+// putBuffer returns buf to bufferPool.
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBuffer {
+		return
+	}
+	buf.Reset()
+	bufferPool.Put(buf)
+}
+
+// writeError logs err and sends the ErrorPage for it. HTTPErrors whose
+// status isn't a 4xx or 5xx code are sent as 500 Internal Server Error.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	status := http.StatusInternalServerError
+	var herr *HTTPError
+	if errors.As(err, &herr) && herr.Status >= 400 && herr.Status <= 599 {
+		status = herr.Status
+	}
+	logf("%s %s: %v", r.Method, r.URL.Path, err)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if err := RenderTo(w, ErrorPage(r, status, err), 0); err != nil {
+		logf("%s %s: rendering error page: %v", r.Method, r.URL.Path, err)
+	}
+}
@@ -0,0 +1,127 @@
+package gohtx
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	h := Handler(func(r *http.Request) (*HtmlTree, error) {
+		switch r.URL.Path {
+		case "/missing":
+			return nil, Errorf(http.StatusNotFound, "no page %s", r.URL.Path)
+		case "/broken":
+			return nil, errors.New("database password is hunter2")
+		case "/gone":
+			return nil, &HTTPError{Status: http.StatusGone}
+		case "/zero":
+			return nil, &HTTPError{}
+		case "/ok":
+			return nil, &HTTPError{Status: http.StatusOK, Err: errors.New("not an error status")}
+		case "/huge":
+			return nil, &HTTPError{Status: 1000}
+		}
+		return P(``, "hello"), nil
+	})
+	var logged bytes.Buffer
+	ErrorLog = log.New(&logged, "", 0)
+	defer func() { ErrorLog = nil }()
+
+	for _, test := range []struct {
+		path   string
+		hx     bool
+		status int
+		body   string
+	}{
+		{"/", false, 200, "<p>hello"},
+		{"/missing", false, 404, "<title>404 Not Found"},
+		{"/missing", false, 404, "<p>no page /missing"},
+		{"/missing", true, 404, `<div class="notification is-danger">no page /missing`},
+		{"/broken", false, 500, "<p>Internal Server Error"},
+		{"/gone", false, 410, "<p>Gone"},
+		{"/gone", true, 410, `<div class="notification is-danger">Gone`},
+		{"/zero", false, 500, "<p>Internal Server Error"},
+		{"/ok", false, 500, "<p>Internal Server Error"},
+		{"/huge", false, 500, "<p>Internal Server Error"},
+	} {
+		r := httptest.NewRequest("GET", test.path, nil)
+		if test.hx {
+			r.Header.Set("HX-Request", "true")
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != test.status {
+			t.Errorf("%s: expected status %d, got %d", test.path, test.status, w.Code)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+			t.Errorf("%s: unexpected Content-Type %q", test.path, ct)
+		}
+		if body := w.Body.String(); !strings.Contains(body, test.body) {
+			t.Errorf("%s: expected %q in\n%s", test.path, test.body, body)
+		}
+		if strings.Contains(w.Body.String(), "hunter2") {
+			t.Errorf("%s: server error details leaked to the client", test.path)
+		}
+		if test.hx && strings.Contains(w.Body.String(), "<html>") {
+			t.Errorf("%s: htmx request got a full page", test.path)
+		}
+	}
+	if !strings.Contains(logged.String(), "hunter2") {
+		t.Errorf("expected server error to be logged, got %q", logged.String())
+	}
+}
+
+func TestHandlerRenderError(t *testing.T) {
+	h := Handler(func(r *http.Request) (*HtmlTree, error) {
+		return Div(``, P(``, "partial"), A(`src="/x"`, "bad")), nil
+	})
+	var logged bytes.Buffer
+	ErrorLog = log.New(&logged, "", 0)
+	defer func() { ErrorLog = nil }()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", w.Code)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, "<p>Internal Server Error") {
+		t.Errorf("expected the error page, got\n%s", body)
+	}
+	if strings.Contains(body, "partial") {
+		t.Errorf("partial response sent before the error page:\n%s", body)
+	}
+	if !strings.Contains(logged.String(), "rendering response") {
+		t.Errorf("expected render error to be logged, got %q", logged.String())
+	}
+}
+
+func TestResponseHandler(t *testing.T) {
+	h := ResponseHandler(func(r *http.Request) (*Response, error) {
+		if r.Method == "DELETE" {
+			return &Response{Status: http.StatusNoContent}, nil
+		}
+		return &Response{
+			Status: http.StatusCreated,
+			Header: http.Header{"Location": {"/items/1"}},
+			Hx:     &HxResponse{Trigger: HxEvents{"itemAdded": nil}},
+			Body:   Li(`id=item-1`, "new"),
+		}, nil
+	})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/items", nil))
+	if w.Code != http.StatusCreated || w.Header().Get("Location") != "/items/1" ||
+		w.Header().Get("HX-Trigger") != "itemAdded" || !strings.Contains(w.Body.String(), "<li id=item-1>new") {
+		t.Errorf("unexpected response %d %v %q", w.Code, w.Header(), w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("DELETE", "/items/1", nil))
+	if w.Code != http.StatusNoContent || w.Body.Len() != 0 {
+		t.Errorf("unexpected response %d %q", w.Code, w.Body.String())
+	}
+}
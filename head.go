@@ -0,0 +1,189 @@
+package gohtx
+
+import (
+	"encoding/json"
+)
+
+// HeadOptions selects the content of the <head> element built by
+// Assets.BuildHeadContent. The zero value gives just the charset and viewport
+// Meta elements. Empty fields are left out.
+type HeadOptions struct {
+	Htmx, Hyperscript, Bulma bool // the embedded libraries to load
+
+	Title       string     // the page title
+	Description string     // the content of the description Meta element
+	Favicon     string     // the URL of the icon, or the name of an embedded asset
+	ThemeColor  string     // the browser UI color, e.g. "#00d1b2"
+	Canonical   string     // the canonical URL of the page
+	OpenGraph   *OpenGraph // Open Graph properties for link previews
+
+	// Extensions names htmx extensions to load, see Extensions. Enable their
+	// attributes once at startup with EnableExtensions.
+	Extensions []string
+
+	// Stylesheets and Scripts are loaded after Bulma and after htmx and its
+	// extensions, respectively. Each is a URL or the name of an embedded
+	// asset, which is loaded from the Assets with its integrity hash.
+	Stylesheets []string
+	Scripts     []string
+
+	// Theme, if not nil, restyles Bulma. Its stylesheet is served with the
+	// assets and loaded right after Bulma, or, if Bulma is false, after
+	// Stylesheets, which should then load Bulma, e.g. purged by PurgeBulma.
+	// The stylesheet is built the first time each Theme is used.
+	Theme *Theme
+
+	// Defer loads the scripts with the defer attribute, so they don't block
+	// parsing. Deferred scripts still run in order.
+	Defer bool
+
+	// Nonce is added to the script and stylesheet elements to allow them
+	// under a Content-Security-Policy with a nonce source. It's also passed
+	// to htmx as its inlineScriptNonce.
+	Nonce string
+}
+
+// OpenGraph holds the Open Graph properties of a page, see https://ogp.me.
+type OpenGraph struct {
+	Title       string
+	Description string
+	Type        string // e.g. "website" or "article"
+	URL         string
+	Image       string
+	SiteName    string
+}
+
+// BuildHeadContent returns the content of the <head> element described by
+// opts, loading the embedded assets from a, e.g.
+//
+//	head, err := assets.BuildHeadContent(HeadOptions{
+//		Htmx: true, Bulma: true,
+//		Title: "Users", Description: "Manage users",
+//		Extensions: []string{"sse"},
+//	})
+//	...
+//	Html(``, Head(``, head), Body(``, ...))
+//
+// It returns an error if opts names an unknown extension or has an invalid
+// Theme.
+func (a *Assets) BuildHeadContent(opts HeadOptions) (*HtmlTree, error) {
+	content := []interface{}{
+		Meta(`charset="utf-8"`),
+		Meta(`name="viewport" content="width=device-width, initial-scale=1"`),
+	}
+	if opts.Title != "" {
+		content = append(content, Title(``, opts.Title))
+	}
+	content = append(content, metaNamed("name", "description", opts.Description)...)
+	content = append(content, metaNamed("name", "theme-color", opts.ThemeColor)...)
+	if opts.Canonical != "" {
+		content = append(content, Link(`rel="canonical"`).With(Attr("href", opts.Canonical)))
+	}
+	if opts.Favicon != "" {
+		content = append(content, Link(`rel="icon"`).With(Attr("href", a.resourceURL(opts.Favicon))))
+	}
+	if og := opts.OpenGraph; og != nil {
+		for _, p := range [][2]string{
+			{"og:title", og.Title},
+			{"og:description", og.Description},
+			{"og:type", og.Type},
+			{"og:url", og.URL},
+			{"og:image", og.Image},
+			{"og:site_name", og.SiteName},
+		} {
+			content = append(content, metaNamed("property", p[0], p[1])...)
+		}
+	}
+
+	// Stylesheets come first so the browser fetches them while it runs
+	// the scripts.
+	var stylesheets, scripts []string
+	if opts.Bulma {
+		stylesheets = append(stylesheets, "bulma/css/bulma.min.css")
+	}
+	stylesheets = append(stylesheets, opts.Stylesheets...)
+	if opts.Theme != nil {
+		name, err := themeAsset(opts.Theme)
+		if err != nil {
+			return nil, err
+		}
+		// The theme overrides Bulma, so it goes right after it.
+		at := len(stylesheets)
+		if opts.Bulma {
+			at = 1
+		}
+		stylesheets = append(stylesheets[:at], append([]string{name}, stylesheets[at:]...)...)
+	}
+	for _, s := range stylesheets {
+		link := Link(`rel="stylesheet" type="text/css"`).With(a.resource("href", s))
+		content = append(content, link.With(nonceAttrs(opts.Nonce)))
+	}
+
+	if opts.Htmx && opts.Nonce != "" {
+		config, err := json.Marshal(map[string]string{"inlineScriptNonce": opts.Nonce})
+		if err != nil {
+			return nil, err
+		}
+		content = append(content, Meta(`name="htmx-config"`).With(Attr("content", string(config))))
+	}
+	if opts.Htmx {
+		scripts = append(scripts, "htmx.min.js")
+	}
+	if opts.Hyperscript {
+		scripts = append(scripts, "hyperscript.js")
+	}
+	files, err := extensionFiles(opts.Extensions)
+	if err != nil {
+		return nil, err
+	}
+	scripts = append(scripts, files...)
+	scripts = append(scripts, opts.Scripts...)
+	for _, s := range scripts {
+		script := Script(``, a.resource("src", s), nonceAttrs(opts.Nonce))
+		if opts.Defer {
+			script = script.With(BoolAttr("defer"))
+		}
+		content = append(content, script)
+	}
+	return Null(content...), nil
+}
+
+// BuildHeadContent returns the head content described by opts with the
+// embedded assets loaded from DefaultAssets.
+func BuildHeadContent(opts HeadOptions) (*HtmlTree, error) {
+	return DefaultAssets.BuildHeadContent(opts)
+}
+
+// resource returns the attributes that load ref from attr. Ref is the name of
+// an embedded asset, which is loaded from a with its integrity hash, or a URL.
+func (a *Assets) resource(attr, ref string) Attrs {
+	if _, ok := lookupAsset(ref); ok {
+		return a.attrs(attr, ref)
+	}
+	return Attrs{attr: ref}
+}
+
+// resourceURL returns the URL of ref, the name of an embedded asset or a URL.
+func (a *Assets) resourceURL(ref string) string {
+	if _, ok := lookupAsset(ref); ok {
+		return a.URL(ref)
+	}
+	return ref
+}
+
+// metaNamed returns a Meta element whose attr, i.e. name or property, is
+// name, with the given content. It returns nothing if content is empty.
+func metaNamed(attr, name, content string) []interface{} {
+	if content == "" {
+		return nil
+	}
+	return []interface{}{Meta(``).With(Attrs{attr: name, "content": content})}
+}
+
+// nonceAttrs returns the nonce attribute, or no attributes if nonce is empty.
+func nonceAttrs(nonce string) Attrs {
+	if nonce == "" {
+		return Attrs{}
+	}
+	return Attrs{"nonce": nonce}
+}
@@ -0,0 +1,94 @@
+package gohtx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestBuildHeadContent(t *testing.T) {
+	a := NewAssets("/static/")
+	head, err := a.BuildHeadContent(HeadOptions{
+		Htmx:        true,
+		Bulma:       true,
+		Title:       "Users & Groups",
+		Description: "Manage users",
+		Favicon:     "https://example.com/favicon.ico",
+		ThemeColor:  "#00d1b2",
+		Canonical:   "https://example.com/users",
+		OpenGraph:   &OpenGraph{Title: "Users", Type: "website", Image: "https://example.com/u.png"},
+		Extensions:  []string{"sse"},
+		Stylesheets: []string{"/css/site.css"},
+		Scripts:     []string{"/js/site.js"},
+		Defer:       true,
+		Nonce:       "r4nd0m",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var perrs []AttributeErrors
+	Html(``, Head(``, head)).CheckAttributes(&perrs)
+	if len(perrs) != 0 {
+		t.Errorf("unexpected attribute errors %v", perrs)
+	}
+	var buf bytes.Buffer
+	if err := Render(head, &buf, -1); err != nil {
+		t.Fatal(err)
+	}
+	s := buf.String()
+	expect := []string{
+		`<meta charset="utf-8">`,
+		`<title>Users &amp; Groups</title>`,
+		`<meta content="Manage users" name="description">`,
+		`<meta content="#00d1b2" name="theme-color">`,
+		`<link rel="canonical" href="https://example.com/users">`,
+		`<link rel="icon" href="https://example.com/favicon.ico">`,
+		`<meta content="Users" property="og:title">`,
+		`<meta content="website" property="og:type">`,
+		`<meta content="https://example.com/u.png" property="og:image">`,
+		`href="` + a.URL("bulma/css/bulma.min.css") + `"`,
+		`<link rel="stylesheet" type="text/css" href="/css/site.css" nonce="r4nd0m">`,
+		`inlineScriptNonce`,
+		`src="` + a.URL("htmx.min.js") + `"`,
+		`src="` + a.URL("ext/sse.js") + `"`,
+		`<script src="/js/site.js" nonce="r4nd0m" defer></script>`,
+	}
+	last := -1
+	for _, want := range expect {
+		i := strings.Index(s, want)
+		if i < 0 {
+			t.Errorf("expected %s in %s", want, s)
+			continue
+		}
+		if i < last {
+			t.Errorf("%s is out of order in %s", want, s)
+		}
+		last = i
+	}
+	for _, unwanted := range []string{"og:description", "og:url", "hyperscript"} {
+		if strings.Contains(s, unwanted) {
+			t.Errorf("unexpected %s in %s", unwanted, s)
+		}
+	}
+	if n := strings.Count(s, `nonce="r4nd0m"`); n != 5 {
+		t.Errorf("expected a nonce on 2 stylesheets and 3 scripts, got %d in %s", n, s)
+	}
+	if n := strings.Count(s, " defer>"); n != 3 {
+		t.Errorf("expected 3 deferred scripts, got %d in %s", n, s)
+	}
+
+	if _, err := BuildHeadContent(HeadOptions{Extensions: []string{"nosuch"}}); err == nil {
+		t.Errorf("expected an error for an unknown extension")
+	}
+	head, err = BuildHeadContent(HeadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err := Render(head, &buf, -1); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(buf.String(), "<") != 2 {
+		t.Errorf("expected only the charset and viewport for the zero options, got %s", buf.String())
+	}
+}
@@ -3,8 +3,12 @@
 package gohtx
 
 import (
+	"bufio"
 	"bytes"
 	"fmt"
+	"html"
+	"html/template"
+	"io"
 	"strings"
 )
 
@@ -12,35 +16,125 @@ import (
 type HtmlTree struct {
 	T     string        // html tagname, e.g. 'head'
 	A     string        // zero or more html attributes, e.g 'id=1 class="foo"'
-	C     []interface{} // a slice of content whose elements may be strings or *HtmlTree
+	C     []interface{} // a slice of content whose elements may be strings, Raw or *HtmlTree
 	empty bool          // set to true for empty tags like <br>
 }
 
+// Raw is content that Render writes verbatim. Plain string content is escaped,
+// so use Raw, or html/template.HTML, only for trusted markup, e.g.
+// Null(Raw("<!DOCTYPE html>"), Html(...)).
+type Raw string
+
+// LegacyRawStrings restores the behavior of earlier versions of gohtx where
+// plain strings are written verbatim, just like Raw. Set it to true only if you
+// have existing trees that embed markup in strings and you trust all of it.
+var LegacyRawStrings = false
+
 // Render walks through HtmlTree h and writes html text to byte buffer b. The
 // nindent argument specifies whether and how much to indent the output where -1
 // means render all on one line and 0 means indent each inner tag by 2 spaces
 // relative to its parent. Render returns an error when it encounters invalid
 // content.
 func Render(h *HtmlTree, b *bytes.Buffer, nindent int) (err error) {
+	return RenderTo(b, h, nindent)
+}
+
+// RenderTo is like Render but writes to any io.Writer, e.g. an
+// http.ResponseWriter, a file or a gzip.Writer. Writes are buffered so that
+// output starts flowing to w before the walk of h is finished. RenderTo stops
+// at the first write error and returns it.
+//
+// Output written before an invalid content error is found can't be taken
+// back, so an http handler that renders straight to the ResponseWriter can't
+// send a 500 for it. Render into a buffer first, as Handler does, or check
+// the tree with CheckAttributes before streaming it.
+func RenderTo(w io.Writer, h *HtmlTree, nindent int) (err error) {
+	rw := newRenderWriter(w)
+	err = render(h, rw, nindent)
+	if ferr := rw.flush(); err == nil {
+		err = ferr
+	}
+	return
+}
+
+// WriteTo implements io.WriterTo. It renders h to w on a single line, i.e.
+// with nindent -1, and returns the number of bytes written.
+func (h *HtmlTree) WriteTo(w io.Writer) (n int64, err error) {
+	rw := newRenderWriter(w)
+	err = render(h, rw, -1)
+	if ferr := rw.flush(); err == nil {
+		err = ferr
+	}
+	n = rw.n
+	return
+}
+
+// renderWriter wraps the destination of a render. It counts the bytes written
+// and remembers the first write error so that render can stop early without
+// checking every write.
+type renderWriter struct {
+	sw  io.StringWriter
+	bw  *bufio.Writer // non-nil if we added buffering and must flush it
+	n   int64
+	err error
+}
+
+// newRenderWriter returns a renderWriter for w. Destinations that are
+// already in-memory or buffered are written directly. Everything else gets a
+// bufio.Writer.
+func newRenderWriter(w io.Writer) *renderWriter {
+	switch w := w.(type) {
+	case *bytes.Buffer:
+		return &renderWriter{sw: w}
+	case *strings.Builder:
+		return &renderWriter{sw: w}
+	case *bufio.Writer:
+		return &renderWriter{sw: w}
+	}
+	bw := bufio.NewWriter(w)
+	return &renderWriter{sw: bw, bw: bw}
+}
+
+// WriteString writes s unless a previous write has failed.
+func (rw *renderWriter) WriteString(s string) {
+	if rw.err != nil {
+		return
+	}
+	n, err := rw.sw.WriteString(s)
+	rw.n += int64(n)
+	rw.err = err
+}
+
+// flush flushes any buffering added by newRenderWriter and returns the first
+// error encountered while writing.
+func (rw *renderWriter) flush() error {
+	if rw.err == nil && rw.bw != nil {
+		rw.err = rw.bw.Flush()
+	}
+	return rw.err
+}
+
+// render does the work of RenderTo. It returns invalid content errors and
+// stops at the first write error recorded in b.
+func render(h *HtmlTree, b *renderWriter, nindent int) (err error) {
+	attrs, errs, err := tagAttributes(h)
+	if err != nil {
+		return
+	}
+	if len(errs) > 0 {
+		err = fmt.Errorf("one or more errors in attributes for tag %s: %v", h.T, errs)
+	}
 	// render the opening tag unless it's the Null tag
 	indent := indentation(nindent)
-	var errs []error
 	if h.T != "null" {
 		b.WriteString(indent)
 		b.WriteString("<")
 		b.WriteString(h.T)
 		// render the attributes
-		if len(h.A) > 0 {
+		if len(attrs) > 0 {
 			b.WriteString(" ")
-			errs, err = checkTagAttributes(h.T, h.A)
-			if err != nil {
-				return
-			}
-			if len(errs) > 0 {
-				err = fmt.Errorf("one or more errors in attributes for tag %s: %v", h.T, errs)
-			}
 		}
-		b.WriteString(h.A)
+		b.WriteString(attrs)
 		// close the opening tag
 		b.WriteString(">")
 	}
@@ -51,21 +145,31 @@ func Render(h *HtmlTree, b *bytes.Buffer, nindent int) (err error) {
 		rindent = nindent + 1
 	}
 	if h.empty {
-		if len(h.C) == 0 {
-			return nil
-		} else {
-			return fmt.Errorf("%s : empty tag may not have content", h.T)
+		for _, c := range h.C {
+			if _, ok := c.(Attributer); !ok {
+				return fmt.Errorf("%s : empty tag may not have content", h.T)
+			}
 		}
+		return
 	}
 	// otherwise, recursively render the content
 	for _, c := range h.C {
+		if b.err != nil {
+			return b.err
+		}
 		switch c := c.(type) {
 		case string:
-			b.WriteString(c)
+			b.WriteString(escapeText(h.T, c))
+		case Raw:
+			b.WriteString(string(c))
+		case template.HTML:
+			b.WriteString(string(c))
+		case Attributer:
+			continue // already rendered in the opening tag
 		case *HtmlTree:
-			err = Render(c, b, rindent)
-			if err != nil {
-				return fmt.Errorf("%s : %v", h.T, err)
+			cerr := render(c, b, rindent)
+			if cerr != nil {
+				return fmt.Errorf("%s : %v", h.T, cerr)
 			}
 		default:
 			return fmt.Errorf("Bad content %v. Can't render type %T! ", h.C, c)
@@ -83,6 +187,48 @@ func Render(h *HtmlTree, b *bytes.Buffer, nindent int) (err error) {
 	return
 }
 
+// escapeText returns string content s escaped as appropriate for the tag that
+// contains it. The content of script and style elements isn't parsed as html
+// by the browser, so only the sequences that would end the element early
+// (or start an html comment) are escaped. Everything else gets ordinary html
+// escaping.
+func escapeText(tag, s string) string {
+	if LegacyRawStrings {
+		return s
+	}
+	switch tag {
+	case "script", "style":
+		return escapeRawText(tag, s)
+	default:
+		return html.EscapeString(s)
+	}
+}
+
+// escapeRawText escapes occurrences of "</tag" and "<!--" in s by inserting a
+// backslash after the '<'. The backslash is harmless inside JavaScript and CSS
+// string literals and is where such sequences can legitimately occur.
+func escapeRawText(tag, s string) string {
+	if !strings.Contains(s, "<") {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		rest := s[i:]
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			sb.WriteString(`<\!--`)
+			i += 3
+			continue
+		case rest[0] == '<' && len(rest) >= 2+len(tag) && rest[1] == '/' && strings.EqualFold(rest[2:2+len(tag)], tag):
+			sb.WriteString(`<\/`)
+			i++
+			continue
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
+
 // indentation returns a string like "\n  " where the number of spaces is n * 2
 // if n is 0 or greater. If n is negative, indentation returns an empty string.
 // The negative case supports rendering an entire tree without newlines or
@@ -117,6 +263,24 @@ func Ids(tree *HtmlTree, ids *[]string) (err error) {
 			n++
 		}
 	}
+	// a structured id attribute replaces any id in the attribute string
+	structured, err := structuredAttributes(tree)
+	if err != nil {
+		return
+	}
+	for _, a := range structured {
+		if a.Name != "id" {
+			continue
+		}
+		if len(a.Value) == 0 {
+			err = fmt.Errorf(`empty id attribute in structured attributes of %s`, tree.T)
+			return
+		}
+		id = a.Value
+		if n == 0 {
+			n = 1
+		}
+	}
 	// if there are more than one, return an error
 	if n > 1 {
 		err = fmt.Errorf("more than one id attribute in '%s' (attributes of %s).", tree.A, tree.T)
@@ -128,7 +292,7 @@ func Ids(tree *HtmlTree, ids *[]string) (err error) {
 	// recurse over the tree content
 	for _, c := range tree.C {
 		switch c := c.(type) {
-		case string: // terminal node for this branch
+		case string, Raw, template.HTML, Attributer: // terminal node for this branch
 			continue
 		case *HtmlTree:
 			err = Ids(c, ids)
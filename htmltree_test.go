@@ -2,6 +2,10 @@ package gohtx
 
 import (
 	"bytes"
+	"errors"
+	"html/template"
+	"io"
+	"strings"
 	"testing"
 
 	"github.com/go-test/deep"
@@ -20,6 +24,11 @@ func TestRender(t *testing.T) {
 		{Br(``), `<br>`},
 		{Null(``), ``},
 		{Null(Br(``), Br(``)), `<br><br>`},
+		{P(``, `<b>"bold" & 'brave'</b>`), `<p>&lt;b&gt;&#34;bold&#34; &amp; &#39;brave&#39;&lt;/b&gt;</p>`},
+		{P(``, Raw(`<b>bold</b>`)), `<p><b>bold</b></p>`},
+		{P(``, template.HTML(`<b>bold</b>`)), `<p><b>bold</b></p>`},
+		{Script(``, `if (a < b && c > d) {s = "</SCRIPT><!--"}`), `<script>if (a < b && c > d) {s = "<\/SCRIPT><\!--"}</script>`},
+		{Style(``, `p > a {content: "</style>"}`), `<style>p > a {content: "<\/style>"}</style>`},
 	}
 	for _, test := range table {
 		var b bytes.Buffer
@@ -34,6 +43,19 @@ func TestRender(t *testing.T) {
 	}
 }
 
+func TestLegacyRawStrings(t *testing.T) {
+	LegacyRawStrings = true
+	defer func() { LegacyRawStrings = false }()
+	var b bytes.Buffer
+	err := Render(P(``, `<b>bold</b>`), &b, -1)
+	if err != nil {
+		t.Errorf("Render failed: %v", err)
+	}
+	if exp := `<p><b>bold</b></p>`; b.String() != exp {
+		t.Errorf("Expected %s, got %s", exp, b.String())
+	}
+}
+
 func BenchmarkRender(b *testing.B) {
 	meta := Meta(`title="Demo"`)
 	head := Head("id=2 class=foo", meta)
@@ -98,3 +120,70 @@ func TestIds(t *testing.T) {
 		}
 	}
 }
+
+// failWriter fails every write after the first n bytes.
+type failWriter struct {
+	n int
+}
+
+func (f *failWriter) Write(p []byte) (int, error) {
+	if len(p) > f.n {
+		n := f.n
+		f.n = 0
+		return n, errors.New("write failed")
+	}
+	f.n -= len(p)
+	return len(p), nil
+}
+
+func TestRenderTo(t *testing.T) {
+	page := Html("", Head(""), Body(`class=foo`, Div("", "hello", Br(``))))
+	var exp bytes.Buffer
+	if err := Render(page, &exp, 0); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	// A writer that isn't already buffered.
+	var sb strings.Builder
+	w := struct{ io.Writer }{&sb}
+	if err := RenderTo(w, page, 0); err != nil {
+		t.Errorf("RenderTo failed: %v", err)
+	}
+	if sb.String() != exp.String() {
+		t.Errorf("Expected %s, got %s", exp.String(), sb.String())
+	}
+	// Write errors must propagate.
+	if err := RenderTo(&failWriter{n: 10}, page, 0); err == nil {
+		t.Errorf("expected a write error, got nil")
+	}
+	// Content errors must propagate too.
+	if err := RenderTo(w, Br(``), 0); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := RenderTo(w, Div(``, 42), 0); err == nil {
+		t.Errorf("expected a content error, got nil")
+	}
+	// Attribute errors in empty tags are reported, so the stylesheet link in
+	// the default head content, which has type="text/css", must be valid.
+	if err := RenderTo(w, Br(`href="/x"`), 0); err == nil {
+		t.Errorf("expected an attribute error for an empty tag, got nil")
+	}
+	if err := RenderTo(w, DefaultHeadContent(), 0); err != nil {
+		t.Errorf("unexpected error rendering the default head content: %v", err)
+	}
+}
+
+func TestWriteTo(t *testing.T) {
+	page := Div(`id=x`, P("", "hello"), Br(``))
+	exp := `<div id=x><p>hello</p><br></div>`
+	var sb strings.Builder
+	n, err := page.WriteTo(&sb)
+	if err != nil {
+		t.Errorf("WriteTo failed: %v", err)
+	}
+	if sb.String() != exp {
+		t.Errorf("Expected %s, got %s", exp, sb.String())
+	}
+	if n != int64(len(exp)) {
+		t.Errorf("Expected n=%d, got %d", len(exp), n)
+	}
+}
@@ -10,7 +10,7 @@ var HxAttrs []string = []string{
 	"hx-push-url",   // pushes the URL into the browser location bar, creating a new history entry
 	"hx-select",     // select content to swap in from a response
 	"hx-select-oob", // select content to swap in from a response, out of band (somewhere other than the target)
-	"hx-swap",       // controls how content is swapped in (outerHTML, beforeEnd, afterend, ...)
+	"hx-swap",       // controls how content is swapped in (outerHTML, beforeend, afterend, ...)
 	"hx-swap-oob",   // marks content in a response to be out of band (should swap in somewhere other than the target)
 	"hx-target",     // specifies the target element to be swapped
 	"hx-trigger",    // specifies the event that triggers the request
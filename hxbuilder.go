@@ -0,0 +1,386 @@
+package gohtx
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Htmx builds htmx attributes with Go method calls instead of attribute
+// strings. Start from the Hx variable and chain methods, then pass the result
+// to a tag function like any other Attributer, e.g.
+//
+//	Button(`class=button`,
+//		Hx.Get("/update").Target("#target").Swap(SwapOuterHTML).
+//			Trigger(On("click").Delay(300*time.Millisecond)),
+//		"Click Me!")
+//
+// Each method returns a new Htmx, so a partially built value may be shared and
+// extended safely. Setting an attribute twice keeps the last value.
+type Htmx struct {
+	attrs []Attribute
+	err   error // the first error from marshaling JSON values
+}
+
+// Hx is the empty Htmx value that starts a chain of builder calls.
+var Hx Htmx
+
+// Attributes implements Attributer. It returns an error if a value passed to
+// Vals, Headers or Request couldn't be marshaled as JSON.
+func (h Htmx) Attributes() ([]Attribute, error) {
+	return h.attrs, h.err
+}
+
+// set returns a copy of h with attribute a replacing any attribute with the
+// same name.
+func (h Htmx) set(a Attribute) Htmx {
+	attrs := make([]Attribute, 0, len(h.attrs)+1)
+	for _, old := range h.attrs {
+		if old.Name != a.Name {
+			attrs = append(attrs, old)
+		}
+	}
+	h.attrs = append(attrs, a)
+	return h
+}
+
+// setJSON returns a copy of h with attribute name set to v marshaled as JSON.
+func (h Htmx) setJSON(name string, v interface{}) Htmx {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		if h.err == nil {
+			h.err = fmt.Errorf("%s: %v", name, err)
+		}
+		return h
+	}
+	return h.set(Attr(name, string(buf)))
+}
+
+// Core attributes
+
+// Boost sets hx-boost.
+func (h Htmx) Boost(on bool) Htmx { return h.set(Attr("hx-boost", strconv.FormatBool(on))) }
+
+// Get sets hx-get to issue a GET to url.
+func (h Htmx) Get(url string) Htmx { return h.set(Attr("hx-get", url)) }
+
+// Post sets hx-post to issue a POST to url.
+func (h Htmx) Post(url string) Htmx { return h.set(Attr("hx-post", url)) }
+
+// PushURL sets hx-push-url to "true", "false" or a URL.
+func (h Htmx) PushURL(url string) Htmx { return h.set(Attr("hx-push-url", url)) }
+
+// Select sets hx-select to the selector of the content to swap in from a
+// response.
+func (h Htmx) Select(selector string) Htmx { return h.set(Attr("hx-select", selector)) }
+
+// SelectOOB sets hx-select-oob to a list of selectors, each optionally
+// followed by a swap style, e.g. "#alert" or "#info:afterbegin".
+func (h Htmx) SelectOOB(selectors ...string) Htmx {
+	return h.set(Attr("hx-select-oob", strings.Join(selectors, ",")))
+}
+
+// Swap sets hx-swap to style followed by any modifiers.
+func (h Htmx) Swap(style SwapStyle, modifiers ...SwapModifier) Htmx {
+	parts := []string{string(style)}
+	for _, m := range modifiers {
+		parts = append(parts, string(m))
+	}
+	return h.set(Attr("hx-swap", strings.Join(parts, " ")))
+}
+
+// SwapOOB sets hx-swap-oob. Use "true" or a swap style, optionally followed by
+// ':' and a selector.
+func (h Htmx) SwapOOB(value string) Htmx { return h.set(Attr("hx-swap-oob", value)) }
+
+// Target sets hx-target to a selector or an htmx extended selector like
+// "closest tr".
+func (h Htmx) Target(selector string) Htmx { return h.set(Attr("hx-target", selector)) }
+
+// Trigger sets hx-trigger to the list of triggers. With no triggers, it
+// returns h unchanged, so the element keeps htmx's default trigger.
+func (h Htmx) Trigger(triggers ...Trigger) Htmx {
+	if len(triggers) == 0 {
+		return h
+	}
+	specs := make([]string, len(triggers))
+	for i, t := range triggers {
+		specs[i] = t.String()
+	}
+	return h.set(Attr("hx-trigger", strings.Join(specs, ", ")))
+}
+
+// Vals sets hx-vals to v marshaled as JSON. v is typically a map or a struct.
+func (h Htmx) Vals(v interface{}) Htmx { return h.setJSON("hx-vals", v) }
+
+// Additional attributes
+
+// Confirm sets hx-confirm to the message of a confirm() dialog.
+func (h Htmx) Confirm(message string) Htmx { return h.set(Attr("hx-confirm", message)) }
+
+// Delete sets hx-delete to issue a DELETE to url.
+func (h Htmx) Delete(url string) Htmx { return h.set(Attr("hx-delete", url)) }
+
+// Disable sets hx-disable to turn off htmx processing.
+func (h Htmx) Disable() Htmx { return h.set(BoolAttr("hx-disable")) }
+
+// Disinherit sets hx-disinherit to the attributes children should not
+// inherit, or to "*" if none are given.
+func (h Htmx) Disinherit(attributes ...string) Htmx {
+	if len(attributes) == 0 {
+		return h.set(Attr("hx-disinherit", "*"))
+	}
+	return h.set(Attr("hx-disinherit", strings.Join(attributes, " ")))
+}
+
+// Encoding sets hx-encoding, e.g. to "multipart/form-data".
+func (h Htmx) Encoding(encoding string) Htmx { return h.set(Attr("hx-encoding", encoding)) }
+
+// Ext sets hx-ext to the list of extensions.
+func (h Htmx) Ext(extensions ...string) Htmx {
+	return h.set(Attr("hx-ext", strings.Join(extensions, ", ")))
+}
+
+// Headers sets hx-headers to v marshaled as JSON.
+func (h Htmx) Headers(v interface{}) Htmx { return h.setJSON("hx-headers", v) }
+
+// HistoryElt sets hx-history-elt.
+func (h Htmx) HistoryElt() Htmx { return h.set(BoolAttr("hx-history-elt")) }
+
+// Include sets hx-include to a selector of additional elements to include.
+func (h Htmx) Include(selector string) Htmx { return h.set(Attr("hx-include", selector)) }
+
+// Indicator sets hx-indicator to a selector of the element that gets the
+// htmx-request class during requests.
+func (h Htmx) Indicator(selector string) Htmx { return h.set(Attr("hx-indicator", selector)) }
+
+// Params sets hx-params, e.g. to "*", "none", "not a,b" or "a,b".
+func (h Htmx) Params(params string) Htmx { return h.set(Attr("hx-params", params)) }
+
+// Patch sets hx-patch to issue a PATCH to url.
+func (h Htmx) Patch(url string) Htmx { return h.set(Attr("hx-patch", url)) }
+
+// Preserve sets hx-preserve.
+func (h Htmx) Preserve() Htmx { return h.set(BoolAttr("hx-preserve")) }
+
+// Prompt sets hx-prompt to the message of a prompt() dialog.
+func (h Htmx) Prompt(message string) Htmx { return h.set(Attr("hx-prompt", message)) }
+
+// Put sets hx-put to issue a PUT to url.
+func (h Htmx) Put(url string) Htmx { return h.set(Attr("hx-put", url)) }
+
+// ReplaceURL sets hx-replace-url to "true", "false" or a URL.
+func (h Htmx) ReplaceURL(url string) Htmx { return h.set(Attr("hx-replace-url", url)) }
+
+// Request sets hx-request to v marshaled as JSON, e.g.
+// map[string]interface{}{"timeout": 500}.
+func (h Htmx) Request(v interface{}) Htmx { return h.setJSON("hx-request", v) }
+
+// SSE sets the legacy hx-sse attribute, e.g. to "connect:/events".
+func (h Htmx) SSE(value string) Htmx { return h.set(Attr("hx-sse", value)) }
+
+// Sync sets hx-sync to selector, followed by ":" and strategy unless strategy
+// is empty.
+func (h Htmx) Sync(selector, strategy string) Htmx {
+	if strategy != "" {
+		selector += ":" + strategy
+	}
+	return h.set(Attr("hx-sync", selector))
+}
+
+// Vars sets the deprecated hx-vars attribute. Use Vals instead.
+func (h Htmx) Vars(expression string) Htmx { return h.set(Attr("hx-vars", expression)) }
+
+// WS sets the legacy hx-ws attribute, e.g. to "connect:/chat".
+func (h Htmx) WS(value string) Htmx { return h.set(Attr("hx-ws", value)) }
+
+// Hyperscript sets the _ attribute to HyperScript code.
+func (h Htmx) Hyperscript(code string) Htmx { return h.set(Attr("_", code)) }
+
+// Script sets the script attribute to HyperScript code.
+func (h Htmx) Script(code string) Htmx { return h.set(Attr("script", code)) }
+
+// Extension attributes
+
+// withExt returns a copy of h with extension added to hx-ext, unless it's
+// already listed.
+func (h Htmx) withExt(extension string) Htmx {
+	var exts []string
+	for _, a := range h.attrs {
+		if a.Name == "hx-ext" {
+			for _, e := range strings.Split(a.Value, ",") {
+				if e = strings.TrimSpace(e); e == extension {
+					return h
+				} else if e != "" {
+					exts = append(exts, e)
+				}
+			}
+		}
+	}
+	return h.Ext(append(exts, extension)...)
+}
+
+// SSEConnect adds sse to hx-ext and sets sse-connect to open a Server-Sent
+// Events stream from url, e.g. one served by an SSEBroker. The sse extension
+// must be loaded and enabled, see HeadOptions.Extensions and EnableExtensions.
+func (h Htmx) SSEConnect(url string) Htmx {
+	return h.withExt("sse").set(Attr("sse-connect", url))
+}
+
+// SSESwap sets sse-swap to swap in the data of the named events from the
+// stream opened by the closest SSEConnect. Use "message" for unnamed events.
+func (h Htmx) SSESwap(events ...string) Htmx {
+	return h.set(Attr("sse-swap", strings.Join(events, ",")))
+}
+
+// WSConnect adds ws to hx-ext and sets ws-connect to open a WebSocket to url,
+// e.g. one served by a WSHub. Relative URLs like "/chat" use the page's host.
+// The ws extension must be loaded and enabled, see HeadOptions.Extensions and
+// EnableExtensions.
+func (h Htmx) WSConnect(url string) Htmx {
+	return h.withExt("ws").set(Attr("ws-connect", url))
+}
+
+// WSSend sets ws-send to send the element's values over the WebSocket opened
+// by the closest WSConnect when it's triggered.
+func (h Htmx) WSSend() Htmx { return h.set(BoolAttr("ws-send")) }
+
+// SwapStyle is an hx-swap strategy.
+type SwapStyle string
+
+const (
+	SwapInnerHTML   SwapStyle = "innerHTML"
+	SwapOuterHTML   SwapStyle = "outerHTML"
+	SwapBeforeBegin SwapStyle = "beforebegin"
+	SwapAfterBegin  SwapStyle = "afterbegin"
+	SwapBeforeEnd   SwapStyle = "beforeend"
+	SwapAfterEnd    SwapStyle = "afterend"
+	SwapDelete      SwapStyle = "delete"
+	SwapNone        SwapStyle = "none"
+)
+
+// SwapModifier is a modifier that follows the style in hx-swap.
+type SwapModifier string
+
+// SwapDelay returns a swap:<d> modifier.
+func SwapDelay(d time.Duration) SwapModifier { return SwapModifier("swap:" + hxDuration(d)) }
+
+// SettleDelay returns a settle:<d> modifier.
+func SettleDelay(d time.Duration) SwapModifier { return SwapModifier("settle:" + hxDuration(d)) }
+
+// SwapScroll returns a scroll modifier. Position is "top" or "bottom". Selector
+// may be empty to scroll the target.
+func SwapScroll(selector, position string) SwapModifier {
+	if selector != "" {
+		position = selector + ":" + position
+	}
+	return SwapModifier("scroll:" + position)
+}
+
+// SwapShow returns a show modifier. Position is "top" or "bottom". Selector may be
+// empty to show the target.
+func SwapShow(selector, position string) SwapModifier {
+	if selector != "" {
+		position = selector + ":" + position
+	}
+	return SwapModifier("show:" + position)
+}
+
+// SwapFocusScroll returns a focus-scroll modifier.
+func SwapFocusScroll(on bool) SwapModifier {
+	return SwapModifier("focus-scroll:" + strconv.FormatBool(on))
+}
+
+// Trigger is one trigger specification for hx-trigger. Create one with On or
+// Every and add modifiers with its methods, e.g.
+// On("keyup").Changed().Delay(500*time.Millisecond).
+type Trigger struct {
+	event     string
+	filter    string
+	modifiers []string
+}
+
+// On returns a Trigger for the named event.
+func On(event string) Trigger { return Trigger{event: event} }
+
+// Every returns a polling Trigger that fires every d.
+func Every(d time.Duration) Trigger { return Trigger{event: "every " + hxDuration(d)} }
+
+// with returns a copy of t with modifier m appended.
+func (t Trigger) with(m string) Trigger {
+	t.modifiers = append(append([]string{}, t.modifiers...), m)
+	return t
+}
+
+// Filter adds an event filter, a JavaScript expression like "ctrlKey".
+func (t Trigger) Filter(expression string) Trigger {
+	t.filter = expression
+	return t
+}
+
+// Changed adds the changed modifier.
+func (t Trigger) Changed() Trigger { return t.with("changed") }
+
+// Once adds the once modifier.
+func (t Trigger) Once() Trigger { return t.with("once") }
+
+// Consume adds the consume modifier.
+func (t Trigger) Consume() Trigger { return t.with("consume") }
+
+// Delay adds a delay:<d> modifier.
+func (t Trigger) Delay(d time.Duration) Trigger { return t.with("delay:" + hxDuration(d)) }
+
+// Throttle adds a throttle:<d> modifier.
+func (t Trigger) Throttle(d time.Duration) Trigger { return t.with("throttle:" + hxDuration(d)) }
+
+// From adds a from:<selector> modifier.
+func (t Trigger) From(selector string) Trigger { return t.with("from:" + selector) }
+
+// Target adds a target:<selector> modifier.
+func (t Trigger) Target(selector string) Trigger { return t.with("target:" + selector) }
+
+// Queue adds a queue modifier. Option is first, last, all or none.
+func (t Trigger) Queue(option string) Trigger { return t.with("queue:" + option) }
+
+// Root adds a root:<selector> modifier for intersect events.
+func (t Trigger) Root(selector string) Trigger { return t.with("root:" + selector) }
+
+// Threshold adds a threshold modifier for intersect events.
+func (t Trigger) Threshold(f float64) Trigger {
+	return t.with("threshold:" + strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+// String returns the specification as it appears in hx-trigger.
+func (t Trigger) String() string {
+	s := t.event
+	if t.filter != "" {
+		if strings.HasPrefix(s, "every ") {
+			s += " "
+		}
+		s += "[" + t.filter + "]"
+	}
+	for _, m := range t.modifiers {
+		s += " " + m
+	}
+	return s
+}
+
+// hxDuration formats d as an htmx time interval, in seconds if d is a whole
+// number of seconds and otherwise in milliseconds, rounded to the nearest
+// millisecond. Durations too short to round to 1ms are sent as 1ms so they
+// don't become 0, and negative durations, which htmx rejects, as 0s.
+func hxDuration(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	if d%time.Second == 0 {
+		return fmt.Sprintf("%ds", d/time.Second)
+	}
+	ms := (d + time.Millisecond/2) / time.Millisecond
+	if ms == 0 {
+		ms = 1
+	}
+	return fmt.Sprintf("%dms", ms)
+}
@@ -0,0 +1,119 @@
+package gohtx
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestHxBuilder(t *testing.T) {
+	type items struct {
+		e   *HtmlTree //what to render
+		exp string    // expected result
+	}
+	base := Hx.Get("/update")
+	table := []items{
+		{Button(`class=button`, base.Target("#target").Swap(SwapOuterHTML).Trigger(On("click").Delay(300*time.Millisecond)), "Go"),
+			`<button class="button" hx-get="/update" hx-target="#target" hx-swap="outerHTML" hx-trigger="click delay:300ms">Go</button>`},
+		// base must not have been modified by the calls above
+		{Div(``, base), `<div hx-get="/update"></div>`},
+		// setting an attribute twice keeps the last value
+		{Div(``, base.Get("/other")), `<div hx-get="/other"></div>`},
+		{Div(``, Hx.Post("/save").Swap(SwapBeforeEnd, SwapDelay(time.Second), SettleDelay(20*time.Millisecond), SwapScroll("#list", "bottom"), SwapFocusScroll(false))),
+			`<div hx-post="/save" hx-swap="beforeend swap:1s settle:20ms scroll:#list:bottom focus-scroll:false"></div>`},
+		{Input(`type=search name=q`).With(Hx.Get("/search").Trigger(On("keyup").Changed().Delay(500*time.Millisecond), On("search"), Every(2*time.Second).Filter("active()"))),
+			`<input type="search" name="q" hx-get="/search" hx-trigger="keyup changed delay:500ms, search, every 2s [active()]">`},
+		{Div(``, Hx.Trigger(On("click").Filter("ctrlKey").Once().From("closest form").Queue("last"))),
+			`<div hx-trigger="click[ctrlKey] once from:closest form queue:last"></div>`},
+		{Section(``, Hx.Vals(map[string]string{"key": `it's "quoted" <b>`})),
+			`<section hx-vals="{&#34;key&#34;:&#34;it&#39;s \&#34;quoted\&#34; \u003cb\u003e&#34;}"></section>`},
+		{Div(``, Hx.Headers(struct {
+			Token string `json:"X-Token"`
+		}{"abc"}).Ext("json-enc").Sync("closest form", "abort").Disinherit().Preserve()),
+			`<div hx-headers="{&#34;X-Token&#34;:&#34;abc&#34;}" hx-ext="json-enc" hx-sync="closest form:abort" hx-disinherit="*" hx-preserve></div>`},
+		{Button(``, Hx.Delete("/item/1").Confirm("Sure?").Script("on click toggle .x")),
+			`<button hx-delete="/item/1" hx-confirm="Sure?" script="on click toggle .x"></button>`},
+	}
+	for _, test := range table {
+		var b bytes.Buffer
+		err := Render(test.e, &b, -1)
+		if err != nil {
+			t.Errorf("Render failed: %v", err)
+		}
+		r := b.String()
+		if r != test.exp {
+			t.Errorf("Expected %s, got %s", test.exp, r)
+		}
+	}
+	// Values that can't be marshaled are reported by Render.
+	var b bytes.Buffer
+	if err := Render(Div(``, Hx.Vals(map[string]interface{}{"f": func() {}})), &b, -1); err == nil {
+		t.Errorf("expected an error for a value that can't be marshaled")
+	}
+}
+
+func TestHxBuilderExtensions(t *testing.T) {
+	if err := EnableExtensions("sse", "ws"); err != nil {
+		t.Fatal(err)
+	}
+	defer DisableExtensions("sse", "ws")
+	for _, test := range []struct {
+		e   *HtmlTree
+		exp string
+	}{
+		{Div(``, Hx.SSEConnect("/events?topic=jobs")), `<div hx-ext="sse" sse-connect="/events?topic=jobs"></div>`},
+		// sse is added to the extensions already listed, once
+		{Div(``, Hx.Ext("json-enc", "preload").SSEConnect("/a").SSEConnect("/b")),
+			`<div hx-ext="json-enc, preload, sse" sse-connect="/b"></div>`},
+		{Div(``, Hx.SSESwap("progress", "done")), `<div sse-swap="progress,done"></div>`},
+		{Div(``, Hx.WSConnect("/chat?room=lobby"), Form(`id="say"`, Hx.WSSend())),
+			`<div hx-ext="ws" ws-connect="/chat?room=lobby"><form id="say" ws-send></form></div>`},
+	} {
+		var b bytes.Buffer
+		if err := Render(test.e, &b, -1); err != nil {
+			t.Fatal(err)
+		}
+		if b.String() != test.exp {
+			t.Errorf("expected %s, got %s", test.exp, b.String())
+		}
+	}
+	var b bytes.Buffer
+	if err := Render(Div(``, Hx.SSESwap("a b")), &b, -1); err == nil {
+		t.Errorf("expected an error for an event name with a space")
+	}
+}
+
+func TestHxDuration(t *testing.T) {
+	for _, test := range []struct {
+		d   time.Duration
+		exp string
+	}{
+		{0, "0s"},
+		{2 * time.Second, "2s"},
+		{300 * time.Millisecond, "300ms"},
+		{1500 * time.Millisecond, "1500ms"},
+		{1500 * time.Microsecond, "2ms"},
+		{1499 * time.Microsecond, "1ms"},
+		{time.Microsecond, "1ms"},
+		{time.Nanosecond, "1ms"},
+		{-5 * time.Millisecond, "0s"},
+	} {
+		if got := hxDuration(test.d); got != test.exp {
+			t.Errorf("hxDuration(%v): expected %q, got %q", test.d, test.exp, got)
+		}
+	}
+}
+
+func TestHxTriggerNone(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Render(Button(``, Hx.Post("/go").Trigger(), "Go"), &buf, -1); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := buf.String(), `<button hx-post="/go">Go</button>`; got != exp {
+		t.Errorf("expected %s, got %s", exp, got)
+	}
+	attrs, _ := Hx.Trigger(On("click")).Trigger().Attributes()
+	if len(attrs) != 1 || attrs[0].Value != "click" {
+		t.Errorf("expected Trigger() to keep the earlier trigger, got %v", attrs)
+	}
+}
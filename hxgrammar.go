@@ -0,0 +1,467 @@
+package gohtx
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// The grammars in this file follow the parsing done by the embedded
+// htmx.min.js (version 1.8.2). Values that htmx would silently misinterpret,
+// like hx-swap="beforeEnd", are reported as errors.
+
+// hxValueGrammars holds the grammars for htmx attribute values. They are
+// added to AttributeValues at init time and apply to the data-hx-* forms too.
+var hxValueGrammars = map[string]ValueGrammar{
+	"hx-boost":       EnumValue("true", "false"),
+	"hx-delete":      URLValue,
+	"hx-disinherit":  hxDisinherit,
+	"hx-encoding":    EnumValue("multipart/form-data"),
+	"hx-ext":         hxExt,
+	"hx-get":         URLValue,
+	"hx-headers":     hxJSON,
+	"hx-include":     hxExtendedSelector,
+	"hx-indicator":   hxExtendedSelector,
+	"hx-params":      hxParams,
+	"hx-patch":       URLValue,
+	"hx-post":        URLValue,
+	"hx-push-url":    hxHistoryURL,
+	"hx-put":         URLValue,
+	"hx-replace-url": hxHistoryURL,
+	"hx-select":      hxSelector,
+	"hx-select-oob":  hxSelectOOB,
+	"hx-swap":        hxSwap,
+	"hx-swap-oob":    hxSwapOOB,
+	"hx-sync":        hxSync,
+	"hx-target":      hxTarget,
+	"hx-trigger":     hxTrigger,
+	"hx-vals":        hxJSON,
+}
+
+// hxSwapStyles are the swap strategies understood by htmx. Any other
+// strategy is silently treated as innerHTML unless an extension handles it.
+var hxSwapStyles = []string{"innerHTML", "outerHTML", "beforebegin", "afterbegin", "beforeend", "afterend", "delete", "none"}
+
+// hxIntervalRE matches the time intervals htmx accepts, e.g. 500ms, 1.5s or 2m.
+// A bare number is in milliseconds.
+var hxIntervalRE = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?(ms|s|m)?$`)
+
+// hxInterval checks a time interval such as the argument of swap: or delay:.
+func hxInterval(v string) error {
+	if !hxIntervalRE.MatchString(v) {
+		return fmt.Errorf("%q is not a time interval like 500ms or 1s", v)
+	}
+	return nil
+}
+
+// splitTopLevel splits s on sep except where sep appears inside brackets,
+// parentheses or quotes.
+func splitTopLevel(s string, sep rune) (parts []string) {
+	var depth int
+	var quote rune
+	start := 0
+	for i, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '[' || r == '(':
+			depth++
+		case r == ']' || r == ')':
+			depth--
+		case r == sep && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// cut slices s around the first instance of sep. It's strings.Cut, which
+// isn't available in go1.16.
+func cut(s, sep string) (before, after string, found bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+// cssSelector makes a few sanity checks of a CSS selector list. It doesn't
+// attempt a full parse, but it catches empty selectors, dangling combinators
+// and unbalanced brackets or quotes.
+func cssSelector(v string) error {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return fmt.Errorf("empty selector")
+	}
+	var depth int
+	var quote rune
+	for _, r := range v {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '[' || r == '(':
+			depth++
+		case r == ']' || r == ')':
+			depth--
+			if depth < 0 {
+				return fmt.Errorf("unbalanced brackets in selector %q", v)
+			}
+		}
+	}
+	if depth != 0 || quote != 0 {
+		return fmt.Errorf("unbalanced brackets or quotes in selector %q", v)
+	}
+	for _, part := range splitTopLevel(v, ',') {
+		part = strings.TrimSpace(part)
+		switch {
+		case part == "":
+			return fmt.Errorf("empty selector in %q", v)
+		case strings.ContainsAny(part[:1], ">+~") || strings.ContainsAny(part[len(part)-1:], ">+~"):
+			return fmt.Errorf("dangling combinator in selector %q", v)
+		}
+	}
+	return nil
+}
+
+// hxSelector accepts a CSS selector.
+func hxSelector(name, v string) error {
+	return cssSelector(v)
+}
+
+// hxExtendedSelector accepts a CSS selector or one of the htmx extensions:
+// this, closest <sel>, find <sel>, next <sel>, previous <sel>, document and
+// window.
+func hxExtendedSelector(name, v string) error {
+	v = strings.TrimSpace(v)
+	switch v {
+	case "this", "document", "window":
+		return nil
+	}
+	for _, prefix := range []string{"closest", "find", "next", "previous"} {
+		if v == prefix {
+			return fmt.Errorf("%s requires a selector, e.g. '%s div'", prefix, prefix)
+		}
+		if strings.HasPrefix(v, prefix+" ") {
+			return cssSelector(v[len(prefix)+1:])
+		}
+	}
+	return cssSelector(v)
+}
+
+// hxTarget accepts the value of hx-target.
+func hxTarget(name, v string) error {
+	return hxExtendedSelector(name, v)
+}
+
+// hxSwapStyle checks a swap strategy.
+func hxSwapStyle(style string) error {
+	if stringInSlice(style, hxSwapStyles) {
+		return nil
+	}
+	for _, s := range hxSwapStyles {
+		if strings.EqualFold(style, s) {
+			return fmt.Errorf("swap style %q must be written %q", style, s)
+		}
+	}
+	return fmt.Errorf("unknown swap style %q, expected one of %s", style, strings.Join(hxSwapStyles, ", "))
+}
+
+// hxSwap accepts a swap strategy followed by optional modifiers, e.g.
+// "outerHTML swap:1s settle:200ms scroll:#list:bottom focus-scroll:true".
+func hxSwap(name, v string) error {
+	fields := strings.Fields(v)
+	if len(fields) == 0 {
+		return fmt.Errorf("missing swap style")
+	}
+	if err := hxSwapStyle(fields[0]); err != nil {
+		return err
+	}
+	for _, m := range fields[1:] {
+		key, arg, found := cut(m, ":")
+		if !found {
+			return fmt.Errorf("unknown swap modifier %q", m)
+		}
+		switch key {
+		case "swap", "settle":
+			if err := hxInterval(arg); err != nil {
+				return fmt.Errorf("%s: %v", key, err)
+			}
+		case "scroll", "show":
+			parts := strings.Split(arg, ":")
+			where := parts[len(parts)-1]
+			if where != "top" && where != "bottom" && !(key == "show" && where == "none") {
+				return fmt.Errorf("%s must end with top or bottom, not %q", key, where)
+			}
+			if len(parts) > 1 {
+				if err := hxExtendedSelector(name, strings.Join(parts[:len(parts)-1], ":")); err != nil {
+					return fmt.Errorf("%s: %v", key, err)
+				}
+			}
+		case "focus-scroll":
+			if arg != "true" && arg != "false" {
+				return fmt.Errorf("focus-scroll must be true or false, not %q", arg)
+			}
+		default:
+			return fmt.Errorf("unknown swap modifier %q", m)
+		}
+	}
+	return nil
+}
+
+// hxSwapOOB accepts "true", a swap style or a swap style with a selector,
+// e.g. "innerHTML:#counter".
+func hxSwapOOB(name, v string) error {
+	if v == "true" {
+		return nil
+	}
+	style, selector, found := cut(v, ":")
+	if err := hxSwapStyle(style); err != nil {
+		return err
+	}
+	if found {
+		return cssSelector(selector)
+	}
+	return nil
+}
+
+// hxSelectOOB accepts a comma-separated list of selectors, each optionally
+// followed by a swap style, e.g. "#alert, #info:afterbegin".
+func hxSelectOOB(name, v string) error {
+	for _, part := range splitTopLevel(v, ',') {
+		selector, style, found := cut(strings.TrimSpace(part), ":")
+		if err := cssSelector(selector); err != nil {
+			return err
+		}
+		if found {
+			if err := hxSwapStyle(style); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// hxTrigger accepts a comma-separated list of trigger specifications like
+// "click[ctrlKey] once, keyup changed delay:500ms from:#search, every 2s".
+func hxTrigger(name, v string) error {
+	for _, spec := range splitTopLevel(v, ',') {
+		if err := hxTriggerSpec(strings.TrimSpace(spec)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hxTriggerSpec checks a single trigger specification.
+func hxTriggerSpec(spec string) error {
+	if spec == "" {
+		return fmt.Errorf("empty trigger")
+	}
+	// The event name runs to the first space or '['.
+	end := strings.IndexAny(spec, " \t\n[")
+	if end < 0 {
+		end = len(spec)
+	}
+	event, rest := spec[:end], spec[end:]
+	rest, err := hxTriggerFilter(rest)
+	if err != nil {
+		return fmt.Errorf("%s: %v", event, err)
+	}
+	fields := strings.Fields(rest)
+	switch {
+	case event == "every":
+		if len(fields) == 0 {
+			return fmt.Errorf("every requires an interval, e.g. 'every 2s'")
+		}
+		if err := hxInterval(fields[0]); err != nil {
+			return fmt.Errorf("every: %v", err)
+		}
+		// A filter may follow the interval.
+		rest, err = hxTriggerFilter(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(rest), fields[0])))
+		if err != nil {
+			return fmt.Errorf("every: %v", err)
+		}
+		if strings.TrimSpace(rest) != "" {
+			return fmt.Errorf("every takes no modifiers: %q", strings.TrimSpace(rest))
+		}
+		return nil
+	case strings.HasPrefix(event, "sse:"):
+		if event == "sse:" {
+			return fmt.Errorf("sse: requires an event name")
+		}
+		if len(fields) > 0 {
+			return fmt.Errorf("%s takes no modifiers", event)
+		}
+		return nil
+	}
+	for i := 0; i < len(fields); i++ {
+		m := fields[i]
+		key, arg, found := cut(m, ":")
+		if !found {
+			if m == "changed" || m == "once" || m == "consume" {
+				continue
+			}
+			return fmt.Errorf("%s: unknown trigger modifier %q", event, m)
+		}
+		switch key {
+		case "delay", "throttle":
+			err = hxInterval(arg)
+		case "from":
+			switch arg {
+			case "closest", "find", "next", "previous":
+				if i+1 == len(fields) {
+					err = fmt.Errorf("from:%s requires a selector", arg)
+					break
+				}
+				i++
+				err = cssSelector(fields[i])
+			case "document", "window":
+			default:
+				err = cssSelector(arg)
+			}
+		case "target", "root":
+			err = cssSelector(arg)
+		case "queue":
+			if !stringInSlice(arg, []string{"first", "last", "all", "none"}) {
+				err = fmt.Errorf("queue must be first, last, all or none, not %q", arg)
+			}
+		case "threshold":
+			err = NumberValue(key, arg)
+		default:
+			err = fmt.Errorf("unknown trigger modifier %q", m)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %v", event, err)
+		}
+	}
+	return nil
+}
+
+// hxTriggerFilter removes a leading event filter like "[ctrlKey]" from s and
+// returns the rest. It checks only that the brackets balance since the filter
+// is a JavaScript expression.
+func hxTriggerFilter(s string) (rest string, err error) {
+	if !strings.HasPrefix(s, "[") {
+		return s, nil
+	}
+	var depth int
+	var quote rune
+	for i, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '[':
+			depth++
+		case r == ']':
+			depth--
+			if depth == 0 {
+				return s[i+1:], nil
+			}
+		}
+	}
+	return "", fmt.Errorf("unterminated event filter %q", s)
+}
+
+// hxSync accepts a selector optionally followed by a strategy, e.g.
+// "closest form:abort" or "this:queue last".
+func hxSync(name, v string) error {
+	parts := strings.Split(v, ":")
+	if len(parts) > 2 {
+		return fmt.Errorf("too many ':' in %q. Selectors containing ':' can't be used with hx-sync", v)
+	}
+	if err := hxExtendedSelector(name, parts[0]); err != nil {
+		return err
+	}
+	if len(parts) == 1 {
+		return nil
+	}
+	strategy := strings.Fields(parts[1])
+	switch {
+	case len(strategy) == 1 && stringInSlice(strategy[0], []string{"drop", "abort", "replace", "queue"}):
+	case len(strategy) == 2 && strategy[0] == "queue" && stringInSlice(strategy[1], []string{"first", "last", "all"}):
+	default:
+		return fmt.Errorf("unknown sync strategy %q, expected drop, abort, replace or queue [first|last|all]", parts[1])
+	}
+	return nil
+}
+
+// hxParams accepts *, none, "not <names>" or a list of names, where names
+// are separated by commas.
+func hxParams(name, v string) error {
+	switch v {
+	case "*", "none":
+		return nil
+	}
+	list := strings.TrimPrefix(v, "not ")
+	for _, p := range strings.Split(list, ",") {
+		if strings.TrimSpace(p) == "" {
+			return fmt.Errorf("empty parameter name in %q", v)
+		}
+	}
+	return nil
+}
+
+// hxJSON accepts the JSON object used by hx-vals and hx-headers. As in htmx,
+// the enclosing braces are optional and a js: or javascript: prefix marks the
+// value as a JavaScript expression, which isn't checked.
+func hxJSON(name, v string) error {
+	v = strings.TrimSpace(v)
+	if strings.HasPrefix(v, "js:") || strings.HasPrefix(v, "javascript:") {
+		return nil
+	}
+	if !strings.HasPrefix(v, "{") {
+		v = "{" + v + "}"
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(v), &m); err != nil {
+		return fmt.Errorf("invalid JSON object: %v", err)
+	}
+	return nil
+}
+
+// hxHistoryURL accepts the value of hx-push-url or hx-replace-url: true,
+// false or a URL.
+func hxHistoryURL(name, v string) error {
+	if v == "true" || v == "false" {
+		return nil
+	}
+	return URLValue(name, v)
+}
+
+// hxDisinherit accepts * or a space-separated list of htmx attribute names.
+func hxDisinherit(name, v string) error {
+	if strings.TrimSpace(v) == "*" {
+		return nil
+	}
+	for _, a := range strings.Fields(v) {
+		if !isValidHxAttribute(a) {
+			return fmt.Errorf("%s is not an htmx attribute", a)
+		}
+	}
+	return nil
+}
+
+// hxExt accepts a comma-separated list of extension names, each optionally
+// prefixed by "ignore:".
+func hxExt(name, v string) error {
+	for _, ext := range strings.Split(v, ",") {
+		ext = strings.TrimPrefix(strings.TrimSpace(ext), "ignore:")
+		if ext == "" || strings.ContainsAny(ext, " \t\n") {
+			return fmt.Errorf("invalid extension name in %q", v)
+		}
+	}
+	return nil
+}
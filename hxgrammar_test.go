@@ -0,0 +1,88 @@
+package gohtx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHxGrammars(t *testing.T) {
+	type items struct {
+		a, v string // attribute and value
+		exp  string // expected substring of the error or "" for none
+	}
+	table := []items{
+		// hx-swap
+		{"hx-swap", "outerHTML", ""},
+		{"hx-swap", "innerHTML swap:1s settle:200ms", ""},
+		{"hx-swap", "beforeend scroll:bottom", ""},
+		{"hx-swap", "beforeend scroll:#list:top show:window:bottom focus-scroll:false", ""},
+		{"hx-swap", "beforeEnd", `swap style "beforeEnd" must be written "beforeend"`},
+		{"hx-swap", "swap:1s", `unknown swap style "swap:1s"`},
+		{"hx-swap", "outerHTML swap:1 sec", `unknown swap modifier "sec"`},
+		{"hx-swap", "outerHTML swap:fast", `swap: "fast" is not a time interval`},
+		{"hx-swap", "outerHTML scroll:middle", "scroll must end with top or bottom"},
+		{"hx-swap", "outerHTML focus-scroll:yes", "focus-scroll must be true or false"},
+		// hx-trigger
+		{"hx-trigger", "click", ""},
+		{"hx-trigger", "click[ctrlKey && shiftKey] once", ""},
+		{"hx-trigger", "keyup changed delay:500ms, search", ""},
+		{"hx-trigger", "every 2s", ""},
+		{"hx-trigger", "every 1s [someCondition()]", ""},
+		{"hx-trigger", "htmx:afterSwap from:closest form throttle:1s queue:last", ""},
+		{"hx-trigger", "click from:document target:#btn consume", ""},
+		{"hx-trigger", "intersect root:#main threshold:0.5", ""},
+		{"hx-trigger", "sse:message", ""},
+		{"hx-trigger", "keyup delay:soon", `keyup: "soon" is not a time interval`},
+		{"hx-trigger", "click twice", `unknown trigger modifier "twice"`},
+		{"hx-trigger", "click[ctrlKey", "unterminated event filter"},
+		{"hx-trigger", "click,", "empty trigger"},
+		{"hx-trigger", "every", "every requires an interval"},
+		{"hx-trigger", "click from:closest", "from:closest requires a selector"},
+		{"hx-trigger", "click queue:some", "queue must be first, last, all or none"},
+		// hx-target
+		{"hx-target", "#target", ""},
+		{"hx-target", "this", ""},
+		{"hx-target", "closest tr", ""},
+		{"hx-target", "find .result", ""},
+		{"hx-target", "next", "next requires a selector"},
+		{"hx-target", "div >", "dangling combinator"},
+		{"hx-target", "input[name='x'", "unbalanced"},
+		// hx-sync
+		{"hx-sync", "closest form:abort", ""},
+		{"hx-sync", "this:queue first", ""},
+		{"hx-sync", "#search", ""},
+		{"hx-sync", "this:wait", `unknown sync strategy "wait"`},
+		// hx-params
+		{"hx-params", "*", ""},
+		{"hx-params", "not secret, token", ""},
+		{"hx-params", "a,,b", "empty parameter name"},
+		// hx-vals and hx-headers
+		{"hx-vals", `{"key": "value", "n": 1}`, ""},
+		{"hx-vals", `"key": "value"`, ""},
+		{"hx-vals", `js:{lastKey: event.key}`, ""},
+		{"hx-vals", `{'key': 'value'}`, "invalid JSON object"},
+		{"hx-headers", `{"X-Token": }`, "invalid JSON object"},
+		// others
+		{"hx-swap-oob", "true", ""},
+		{"hx-swap-oob", "innerHTML:#counter", ""},
+		{"hx-swap-oob", "inner", "unknown swap style"},
+		{"hx-select-oob", "#alert, #info:afterbegin", ""},
+		{"hx-push-url", "false", ""},
+		{"hx-boost", "yes", `must be one of "true", "false"`},
+		{"hx-disinherit", "hx-target hx-select", ""},
+		{"hx-disinherit", "hx-taget", "hx-taget is not an htmx attribute"},
+		{"hx-ext", "json-enc, ignore:sse", ""},
+		{"data-hx-swap", "outerhtml", `must be written "outerHTML"`},
+	}
+	for _, test := range table {
+		err := checkAttrValue("div", test.a, test.v)
+		switch {
+		case err == nil && test.exp != "":
+			t.Errorf("%s=%q: expected error containing %q, got nil", test.a, test.v, test.exp)
+		case err != nil && test.exp == "":
+			t.Errorf("%s=%q: unexpected error %v", test.a, test.v, err)
+		case err != nil && !strings.Contains(err.Error(), test.exp):
+			t.Errorf("%s=%q: expected error containing %q, got %v", test.a, test.v, test.exp, err)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package gohtx
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// HxRequest holds the request headers htmx sends with each request it makes.
+// See https://htmx.org/reference/#request_headers
+type HxRequest struct {
+	Request               bool   // HX-Request: always true for requests made by htmx
+	Boosted               bool   // HX-Boosted: the request came from an element using hx-boost
+	CurrentURL            string // HX-Current-URL: the current URL of the browser
+	HistoryRestoreRequest bool   // HX-History-Restore-Request: the request restores history after a local cache miss
+	Prompt                string // HX-Prompt: the user's response to an hx-prompt
+	Target                string // HX-Target: the id of the target element, if it has one
+	Trigger               string // HX-Trigger: the id of the triggering element, if it has one
+	TriggerName           string // HX-Trigger-Name: the name of the triggering element, if it has one
+}
+
+// ParseHxRequest returns the htmx request headers of r. For requests that
+// weren't made by htmx, all the fields have their zero values.
+func ParseHxRequest(r *http.Request) HxRequest {
+	return HxRequest{
+		Request:               hxHeader(r, "HX-Request") == "true",
+		Boosted:               hxHeader(r, "HX-Boosted") == "true",
+		CurrentURL:            hxHeader(r, "HX-Current-URL"),
+		HistoryRestoreRequest: hxHeader(r, "HX-History-Restore-Request") == "true",
+		Prompt:                hxHeader(r, "HX-Prompt"),
+		Target:                hxHeader(r, "HX-Target"),
+		Trigger:               hxHeader(r, "HX-Trigger"),
+		TriggerName:           hxHeader(r, "HX-Trigger-Name"),
+	}
+}
+
+// hxHeader returns the value of the named request header. htmx URI encodes
+// values that contain characters not allowed in headers, e.g. a prompt
+// response in Greek, and flags them with a second header that's decoded here.
+func hxHeader(r *http.Request, name string) string {
+	value := r.Header.Get(name)
+	if r.Header.Get(name+"-URI-AutoEncoded") == "true" {
+		if decoded, err := url.PathUnescape(value); err == nil {
+			value = decoded
+		}
+	}
+	return value
+}
+
+// IsHxRequest reports whether r was made by htmx.
+func IsHxRequest(r *http.Request) bool {
+	return r.Header.Get("HX-Request") == "true"
+}
+
+// WantsFragment reports whether the response should be a fragment to be
+// swapped into the current page rather than a full page. Boosted requests and
+// history restores replace the whole body, so they need a full page, as do
+// requests that didn't come from htmx.
+func (hx HxRequest) WantsFragment() bool {
+	return hx.Request && !hx.Boosted && !hx.HistoryRestoreRequest
+}
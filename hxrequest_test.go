@@ -0,0 +1,46 @@
+package gohtx
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseHxRequest(t *testing.T) {
+	r := httptest.NewRequest("GET", "/update", nil)
+	if hx := ParseHxRequest(r); hx != (HxRequest{}) || IsHxRequest(r) || hx.WantsFragment() {
+		t.Errorf("plain request parsed as %+v", hx)
+	}
+
+	r.Header.Set("HX-Request", "true")
+	r.Header.Set("HX-Current-URL", "http://localhost:8080/")
+	r.Header.Set("HX-Target", "target")
+	r.Header.Set("HX-Trigger", "btn")
+	r.Header.Set("HX-Trigger-Name", "go")
+	r.Header.Set("HX-Prompt", "caf%C3%A9%20au%20lait+")
+	r.Header.Set("HX-Prompt-URI-AutoEncoded", "true")
+	expect := HxRequest{
+		Request:     true,
+		CurrentURL:  "http://localhost:8080/",
+		Prompt:      "café au lait+",
+		Target:      "target",
+		Trigger:     "btn",
+		TriggerName: "go",
+	}
+	hx := ParseHxRequest(r)
+	if hx != expect {
+		t.Errorf("expected %+v, got %+v", expect, hx)
+	}
+	if !IsHxRequest(r) || !hx.WantsFragment() {
+		t.Errorf("htmx request should want a fragment")
+	}
+
+	r.Header.Set("HX-Boosted", "true")
+	if hx := ParseHxRequest(r); !hx.Boosted || hx.WantsFragment() {
+		t.Errorf("boosted request should want a full page: %+v", hx)
+	}
+	r.Header.Del("HX-Boosted")
+	r.Header.Set("HX-History-Restore-Request", "true")
+	if hx := ParseHxRequest(r); !hx.HistoryRestoreRequest || hx.WantsFragment() {
+		t.Errorf("history restore should want a full page: %+v", hx)
+	}
+}
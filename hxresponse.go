@@ -0,0 +1,176 @@
+package gohtx
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+)
+
+// HxResponse holds the response headers that tell htmx what to do with a
+// response beyond swapping it into the target. Fields left at their zero
+// values aren't sent. See https://htmx.org/reference/#response_headers
+type HxResponse struct {
+	Redirect   string      // HX-Redirect: a URL to load in place of the current page
+	Refresh    bool        // HX-Refresh: reload the whole page
+	Location   *HxLocation // HX-Location: an ajax request to make, without a full reload
+	PushURL    string      // HX-Push-Url: a URL to push into history or "false" to prevent it
+	ReplaceURL string      // HX-Replace-Url: a URL to replace the current location or "false"
+	Reswap     string      // HX-Reswap: an hx-swap value, e.g. "outerHTML swap:1s"
+	Retarget   string      // HX-Retarget: a CSS selector for a new target
+	Reselect   string      // HX-Reselect: a CSS selector for the part of the response to swap (htmx 1.9 and later)
+
+	// The events to trigger on the target once the response is received,
+	// swapped and settled.
+	Trigger            HxEvents // HX-Trigger
+	TriggerAfterSwap   HxEvents // HX-Trigger-After-Swap
+	TriggerAfterSettle HxEvents // HX-Trigger-After-Settle
+}
+
+// HxLocation is the JSON form of HX-Location. Path is required. The other
+// fields override the defaults of the ajax request htmx makes to Path.
+type HxLocation struct {
+	Path    string            `json:"path"`
+	Source  string            `json:"source,omitempty"`  // the source element of the request
+	Event   string            `json:"event,omitempty"`   // an event that "triggered" the request
+	Handler string            `json:"handler,omitempty"` // a callback that handles the response
+	Target  string            `json:"target,omitempty"`  // the target to swap the response into
+	Swap    string            `json:"swap,omitempty"`    // how the response is swapped in
+	Values  interface{}       `json:"values,omitempty"`  // values to submit with the request
+	Headers map[string]string `json:"headers,omitempty"` // headers to submit with the request
+}
+
+// HxEvents maps the names of events to trigger on the client to their
+// details. The details are marshaled as JSON and are available to listeners
+// as event.detail, or event.detail.value when the detail isn't an object. Use
+// nil for events without details.
+type HxEvents map[string]interface{}
+
+// SetHeaders sets the headers of hx in header. It returns an error if a value
+// can't be marshaled as JSON, in which case no headers are set.
+func (hx *HxResponse) SetHeaders(header http.Header) error {
+	values := make(map[string]string)
+	set := func(name, value string) {
+		if value != "" {
+			values[name] = value
+		}
+	}
+	set("HX-Redirect", hx.Redirect)
+	if hx.Refresh {
+		set("HX-Refresh", "true")
+	}
+	if hx.Location != nil {
+		location, err := hx.Location.headerValue()
+		if err != nil {
+			return err
+		}
+		set("HX-Location", location)
+	}
+	set("HX-Push-Url", hx.PushURL)
+	set("HX-Replace-Url", hx.ReplaceURL)
+	set("HX-Reswap", hx.Reswap)
+	set("HX-Retarget", hx.Retarget)
+	set("HX-Reselect", hx.Reselect)
+	for name, events := range map[string]HxEvents{
+		"HX-Trigger":              hx.Trigger,
+		"HX-Trigger-After-Swap":   hx.TriggerAfterSwap,
+		"HX-Trigger-After-Settle": hx.TriggerAfterSettle,
+	} {
+		value, err := events.headerValue()
+		if err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		set(name, value)
+	}
+	for name, value := range values {
+		header.Set(name, value)
+	}
+	return nil
+}
+
+// headerValue returns the value of the HX-Location header for l, which is
+// just the path unless other fields are set.
+func (l *HxLocation) headerValue() (string, error) {
+	if l.Path == "" {
+		return "", fmt.Errorf("HX-Location: path is required")
+	}
+	if l.Source == "" && l.Event == "" && l.Handler == "" && l.Target == "" && l.Swap == "" &&
+		l.Values == nil && l.Headers == nil {
+		return l.Path, nil
+	}
+	buf, err := json.Marshal(l)
+	if err != nil {
+		return "", fmt.Errorf("HX-Location: %v", err)
+	}
+	return asciiJSON(buf), nil
+}
+
+// headerValue returns the value of a trigger header for events. A single
+// event without details is sent by name. Anything else is sent as a JSON
+// object because htmx treats any other plain value as one event name.
+func (events HxEvents) headerValue() (string, error) {
+	if len(events) == 1 {
+		for name, detail := range events {
+			if detail == nil {
+				return name, nil
+			}
+		}
+	}
+	if len(events) == 0 {
+		return "", nil
+	}
+	buf, err := json.Marshal(events)
+	if err != nil {
+		return "", err
+	}
+	return asciiJSON(buf), nil
+}
+
+// asciiJSON returns buf with non-ASCII characters written as \u escapes.
+// Header values are interpreted as Latin-1 by browsers, so UTF-8 in them
+// would be garbled.
+func asciiJSON(buf []byte) string {
+	out := make([]byte, 0, len(buf))
+	for len(buf) > 0 {
+		r, size := utf8.DecodeRune(buf)
+		buf = buf[size:]
+		switch {
+		case r < utf8.RuneSelf:
+			out = append(out, byte(r))
+		case r > 0xffff:
+			r -= 0x10000
+			out = appendUnicodeEscape(out, 0xd800+(r>>10))
+			out = appendUnicodeEscape(out, 0xdc00+(r&0x3ff))
+		default:
+			out = appendUnicodeEscape(out, r)
+		}
+	}
+	return string(out)
+}
+
+// appendUnicodeEscape appends r to out as a JSON \u escape.
+func appendUnicodeEscape(out []byte, r rune) []byte {
+	hex := strconv.FormatInt(int64(r), 16)
+	out = append(out, `\u`...)
+	for i := len(hex); i < 4; i++ {
+		out = append(out, '0')
+	}
+	return append(out, hex...)
+}
+
+// RenderResponse writes h to w as html, preceded by the htmx response headers
+// in hx, which may be nil. It sets the Content-Type to html unless the
+// handler has already set it. Errors setting the headers are returned before
+// anything is written, so the handler can still send an error status.
+func RenderResponse(w http.ResponseWriter, h *HtmlTree, hx *HxResponse) error {
+	if hx != nil {
+		if err := hx.SetHeaders(w.Header()); err != nil {
+			return err
+		}
+	}
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	}
+	return RenderTo(w, h, 0)
+}
@@ -0,0 +1,86 @@
+package gohtx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHxResponseSetHeaders(t *testing.T) {
+	hx := HxResponse{
+		Redirect:   "/login",
+		Refresh:    true,
+		PushURL:    "false",
+		ReplaceURL: "/items?page=2",
+		Reswap:     "outerHTML swap:1s",
+		Retarget:   "#errors",
+		Reselect:   "#content",
+		Location: &HxLocation{
+			Path:   "/items",
+			Target: "#list",
+			Values: map[string]int{"page": 2},
+		},
+		Trigger:            HxEvents{"itemAdded": nil},
+		TriggerAfterSwap:   HxEvents{"notify": map[string]string{"msg": "Café saved"}},
+		TriggerAfterSettle: HxEvents{"a": nil, "b": 3},
+	}
+	header := make(http.Header)
+	if err := hx.SetHeaders(header); err != nil {
+		t.Fatal(err)
+	}
+	expect := map[string]string{
+		"HX-Redirect":             "/login",
+		"HX-Refresh":              "true",
+		"HX-Push-Url":             "false",
+		"HX-Replace-Url":          "/items?page=2",
+		"HX-Reswap":               "outerHTML swap:1s",
+		"HX-Retarget":             "#errors",
+		"HX-Reselect":             "#content",
+		"HX-Location":             `{"path":"/items","target":"#list","values":{"page":2}}`,
+		"HX-Trigger":              "itemAdded",
+		"HX-Trigger-After-Swap":   `{"notify":{"msg":"Caf\u00e9 saved"}}`,
+		"HX-Trigger-After-Settle": `{"a":null,"b":3}`,
+	}
+	for name, value := range expect {
+		if got := header.Get(name); got != value {
+			t.Errorf("%s: expected %s, got %s", name, value, got)
+		}
+	}
+	if len(header) != len(expect) {
+		t.Errorf("expected %d headers, got %v", len(expect), header)
+	}
+
+	header = make(http.Header)
+	hx = HxResponse{Location: &HxLocation{Path: "/items"}}
+	if err := hx.SetHeaders(header); err != nil || header.Get("HX-Location") != "/items" {
+		t.Errorf("expected plain HX-Location path, got %v (%v)", header, err)
+	}
+
+	header = make(http.Header)
+	hx = HxResponse{Retarget: "#x", Trigger: HxEvents{"bad": func() {}}}
+	if err := hx.SetHeaders(header); err == nil || len(header) != 0 {
+		t.Errorf("expected error and no headers, got %v (%v)", header, err)
+	}
+	hx = HxResponse{Location: &HxLocation{Target: "#x"}}
+	if err := hx.SetHeaders(header); err == nil {
+		t.Errorf("expected error for HX-Location without a path")
+	}
+}
+
+func TestRenderResponse(t *testing.T) {
+	w := httptest.NewRecorder()
+	err := RenderResponse(w, Div(`id=x`, "hi"), &HxResponse{Trigger: HxEvents{"done": nil}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Errorf("unexpected Content-Type %q", ct)
+	}
+	if tr := w.Header().Get("HX-Trigger"); tr != "done" {
+		t.Errorf("unexpected HX-Trigger %q", tr)
+	}
+	if body := w.Body.String(); !strings.Contains(body, "<div id=x>hi") {
+		t.Errorf("unexpected body %q", body)
+	}
+}
@@ -0,0 +1,111 @@
+package gohtx
+
+import (
+	"fmt"
+)
+
+// OOBResponse composes a response from a primary fragment, which htmx swaps
+// into the target of the request, and any number of out-of-band fragments,
+// which it swaps in elsewhere on the page by id. Build one with
+// NewOOBResponse, add fragments with OOB and render the result of Tree, e.g.
+//
+//	tree, err := NewOOBResponse(row).
+//		OOB(Span(`id=count`, n), SwapOuterHTML, "").
+//		OOB(Div(``, notice), SwapBeforeEnd, "notifications").
+//		Tree()
+type OOBResponse struct {
+	primary   *HtmlTree
+	fragments []interface{}
+	err       error // the first error from OOB
+}
+
+// NewOOBResponse returns an OOBResponse with primary as its main content.
+// Primary may be nil for a response made only of out-of-band swaps.
+func NewOOBResponse(primary *HtmlTree) *OOBResponse {
+	return &OOBResponse{primary: primary}
+}
+
+// OOB adds fragment to r as an out-of-band swap and returns r. The swap
+// replaces the element with id targetID, or the element with the same id as
+// fragment when targetID is empty. Swapping with SwapOuterHTML, or an empty
+// style, replaces the target with fragment. Other styles swap in the content
+// of fragment, leaving its own tag behind. Fragment is copied before the
+// hx-swap-oob attribute is added, so it isn't modified. Errors are reported
+// by Tree.
+func (r *OOBResponse) OOB(fragment *HtmlTree, style SwapStyle, targetID string) *OOBResponse {
+	if r.err != nil {
+		return r
+	}
+	marked, err := oobFragment(fragment, style, targetID)
+	if err != nil {
+		r.err = err
+		return r
+	}
+	r.fragments = append(r.fragments, marked)
+	return r
+}
+
+// Tree returns the primary fragment followed by the out-of-band fragments as
+// a single tree ready to render. It returns an error if a fragment couldn't
+// be added or if any id appears more than once in the response.
+func (r *OOBResponse) Tree() (*HtmlTree, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	var content []interface{}
+	if r.primary != nil {
+		content = append(content, r.primary)
+	}
+	tree := Null(append(content, r.fragments...)...)
+	var ids []string
+	if err := Ids(tree, &ids); err != nil {
+		return nil, fmt.Errorf("out-of-band response: %v", err)
+	}
+	return tree, nil
+}
+
+// oobFragment returns a copy of fragment with an hx-swap-oob attribute that
+// swaps it into targetID with style.
+func oobFragment(fragment *HtmlTree, style SwapStyle, targetID string) (*HtmlTree, error) {
+	if fragment == nil || fragment.T == "null" || fragment.T == "" || fragment.empty && style != SwapOuterHTML && style != "" {
+		return nil, fmt.Errorf("out-of-band fragments must be elements with content")
+	}
+	var ids []string
+	if err := Ids(&HtmlTree{T: fragment.T, A: fragment.A, C: attributers(fragment)}, &ids); err != nil {
+		return nil, err
+	}
+	var value string
+	switch {
+	case targetID == "" && len(ids) == 0:
+		return nil, fmt.Errorf("out-of-band %s fragment needs an id or a target id", fragment.T)
+	case targetID == "" || len(ids) == 1 && ids[0] == targetID:
+		value = string(style)
+		if value == "" {
+			value = "true"
+		}
+	default:
+		if err := IDValue("id", targetID); err != nil {
+			return nil, fmt.Errorf("target id %q %v", targetID, err)
+		}
+		if style == "" {
+			style = SwapOuterHTML
+		}
+		value = fmt.Sprintf("%s:#%s", style, targetID)
+	}
+	if err := checkAttrValue(fragment.T, "hx-swap-oob", value); err != nil {
+		return nil, err
+	}
+	marked := *fragment
+	marked.C = append(append([]interface{}{}, fragment.C...), Attr("hx-swap-oob", value))
+	return &marked, nil
+}
+
+// attributers returns the Attributer values in the content of h.
+func attributers(h *HtmlTree) (content []interface{}) {
+	for _, c := range h.C {
+		if a, ok := c.(Attributer); ok {
+			content = append(content, a)
+		}
+	}
+	return
+}
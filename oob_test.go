@@ -0,0 +1,51 @@
+package gohtx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestOOBResponse(t *testing.T) {
+	counter := Span(`id=count`, "3")
+	tree, err := NewOOBResponse(Tr(`id=row-1`, Td(``, "updated"))).
+		OOB(counter, "", "").
+		OOB(Div(``, "Saved"), SwapBeforeEnd, "notifications").
+		OOB(Td(`id=total`, "9"), SwapInnerHTML, "total").
+		Tree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := Render(tree, &buf, -1); err != nil {
+		t.Fatal(err)
+	}
+	expect := `<tr id=row-1><td>updated</td></tr>` +
+		`<span id="count" hx-swap-oob="true">3</span>` +
+		`<div hx-swap-oob="beforeend:#notifications">Saved</div>` +
+		`<td id="total" hx-swap-oob="innerHTML">9</td>`
+	if got := buf.String(); got != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, got)
+	}
+	if len(counter.C) != 1 {
+		t.Errorf("OOB modified its fragment: %v", counter.C)
+	}
+}
+
+func TestOOBResponseErrors(t *testing.T) {
+	for _, test := range []struct {
+		response *OOBResponse
+		expect   string
+	}{
+		{NewOOBResponse(nil).OOB(Div(``, "x"), SwapOuterHTML, ""), "needs an id"},
+		{NewOOBResponse(nil).OOB(Null("x"), SwapOuterHTML, "a"), "must be elements"},
+		{NewOOBResponse(nil).OOB(Div(`id=a`), SwapStyle("middle"), ""), "hx-swap-oob"},
+		{NewOOBResponse(nil).OOB(Div(``), SwapInnerHTML, "a b"), "target id"},
+		{NewOOBResponse(Div(`id=a`)).OOB(Div(`id=a`), "", ""), "duplicated id a"},
+	} {
+		_, err := test.response.Tree()
+		if err == nil || !strings.Contains(err.Error(), test.expect) {
+			t.Errorf("expected error containing %q, got %v", test.expect, err)
+		}
+	}
+}
@@ -0,0 +1,360 @@
+package gohtx
+
+import (
+	"html/template"
+	"strings"
+
+	nethtml "golang.org/x/net/html"
+)
+
+// implicitTags are the elements browsers add to every page, or every table,
+// whether or not the tree has them.
+var implicitTags = []string{"html", "head", "body", "tbody"}
+
+// PurgeBulma returns the rules of the embedded bulma.min.css that can match
+// elements of pages. See PurgeCSS, and note that pages must include the
+// fragments the server sends later, not just the full pages.
+func PurgeBulma(pages []*HtmlTree, keep ...string) string {
+	css, err := staticFiles.ReadFile("bulma/css/bulma.min.css")
+	if err != nil {
+		// The FS is compiled in, so failure is a programming error.
+		panic(err)
+	}
+	return PurgeCSS(string(css), pages, keep...)
+}
+
+// PurgeCSS returns the rules of css that can match elements of pages, so a
+// site can serve a fraction of a large stylesheet like Bulma's. Serve the
+// result with AddAsset.
+//
+// Only the trees in pages are scanned. Pass every tree the site sends: the
+// full pages, and the fragments that handlers return to htmx requests,
+// OOBResponses and the messages sent by SSEBroker and WSHub, built with
+// representative data. The rules for classes used only in fragments that
+// aren't passed are purged, and the fragments are unstyled when swapped in.
+//
+// A selector is kept if the pages use all the tags, classes and ids it names.
+// Conditions in brackets and parentheses, like [disabled] or :not(.is-active),
+// are ignored, so some selectors are kept that never match. Classes added by
+// scripts are found if they appear in an attribute, e.g. a HyperScript _
+// attribute, or a Script element of the pages, and the classes htmx adds are
+// always kept. Pass any others in keep. Elements in Raw content are included. @media and
+// @supports rules keep the rules inside them that match, and other at-rules,
+// like @keyframes and @font-face, are kept whole, as are rules with no
+// selectors to match, like :root, which defines variables.
+func PurgeCSS(css string, pages []*HtmlTree, keep ...string) string {
+	u := newUsedNames()
+	for _, tag := range implicitTags {
+		u.tags[tag] = true
+	}
+	for _, name := range append(htmxClasses, keep...) {
+		u.words[name] = true
+	}
+	for _, page := range pages {
+		u.addTree(page)
+	}
+	var sb strings.Builder
+	purgeRules(&sb, stripComments(css), u, "\n")
+	return sb.String()
+}
+
+// usedNames holds the tags, classes and ids used by a set of pages. Words
+// holds the identifiers found in other attribute values and scripts, which
+// may name classes or ids.
+type usedNames struct {
+	tags, classes, ids, words map[string]bool
+}
+
+// newUsedNames returns an empty usedNames.
+func newUsedNames() *usedNames {
+	return &usedNames{
+		tags:    make(map[string]bool),
+		classes: make(map[string]bool),
+		ids:     make(map[string]bool),
+		words:   make(map[string]bool),
+	}
+}
+
+// addTree adds the names used by h and its descendants.
+func (u *usedNames) addTree(h *HtmlTree) {
+	if h.T != "null" && h.T != "!--" {
+		u.tags[h.T] = true
+		attrs, _, err := tagAttributes(h)
+		if err == nil {
+			if parsed, err := parseAttributes(attrs); err == nil {
+				u.addAttributes(parsed)
+			}
+		}
+	}
+	for _, c := range h.C {
+		switch t := c.(type) {
+		case *HtmlTree:
+			u.addTree(t)
+		case Raw:
+			u.addHTML(string(t))
+		case template.HTML:
+			u.addHTML(string(t))
+		case string:
+			if h.T == "script" {
+				u.addWords(t)
+			}
+		}
+	}
+}
+
+// addAttributes adds the classes, id and words in attrs.
+func (u *usedNames) addAttributes(attrs []Attribute) {
+	for _, a := range attrs {
+		switch a.Name {
+		case "class":
+			for _, name := range strings.Fields(a.Value) {
+				u.classes[name] = true
+			}
+		case "id":
+			u.ids[a.Value] = true
+		default:
+			u.addWords(a.Value)
+		}
+	}
+}
+
+// addHTML adds the names used by the elements in an html fragment.
+func (u *usedNames) addHTML(fragment string) {
+	z := nethtml.NewTokenizer(strings.NewReader(fragment))
+	inScript := false
+	for {
+		switch z.Next() {
+		case nethtml.ErrorToken:
+			return
+		case nethtml.StartTagToken, nethtml.SelfClosingTagToken:
+			t := z.Token()
+			u.tags[t.Data] = true
+			inScript = t.Data == "script"
+			attrs := make([]Attribute, len(t.Attr))
+			for i, a := range t.Attr {
+				attrs[i] = Attribute{Name: a.Key, Value: a.Val}
+			}
+			u.addAttributes(attrs)
+		case nethtml.TextToken:
+			if inScript {
+				u.addWords(string(z.Text()))
+			}
+		case nethtml.EndTagToken:
+			inScript = false
+		}
+	}
+}
+
+// addWords adds the identifiers in s.
+func (u *usedNames) addWords(s string) {
+	for _, word := range strings.FieldsFunc(s, func(r rune) bool {
+		return r < 0x80 && !isIdentByte(byte(r))
+	}) {
+		u.words[word] = true
+	}
+}
+
+// purgeRules writes the rules of css that can match the names in u to sb,
+// each followed by sep.
+func purgeRules(sb *strings.Builder, css string, u *usedNames, sep string) {
+	for len(css) > 0 {
+		prelude, block, isBlock, rest := nextRule(css)
+		css = rest
+		prelude = strings.TrimSpace(prelude)
+		switch {
+		case prelude == "" && !isBlock:
+			continue
+		case !isBlock: // a statement, like @charset or @import
+			sb.WriteString(prelude + ";" + sep)
+		case strings.HasPrefix(prelude, "@media") || strings.HasPrefix(prelude, "@supports"):
+			var inner strings.Builder
+			purgeRules(&inner, block, u, "")
+			if inner.Len() > 0 {
+				sb.WriteString(prelude + "{" + inner.String() + "}" + sep)
+			}
+		case strings.HasPrefix(prelude, "@"):
+			sb.WriteString(prelude + "{" + block + "}" + sep)
+		default:
+			var kept []string
+			for _, selector := range splitList(prelude, ',') {
+				if u.mayMatch(selector) {
+					kept = append(kept, selector)
+				}
+			}
+			if len(kept) > 0 {
+				sb.WriteString(strings.Join(kept, ",") + "{" + block + "}" + sep)
+			}
+		}
+	}
+}
+
+// nextRule returns the prelude of the first rule in css, its block if it has
+// one, and the rest of css.
+func nextRule(css string) (prelude, block string, isBlock bool, rest string) {
+	for i := 0; i < len(css); i++ {
+		switch c := css[i]; c {
+		case '"', '\'':
+			i = skipString(css, i)
+		case '\\':
+			i++
+		case ';':
+			return css[:i], "", false, css[i+1:]
+		case '{':
+			end := matchingBrace(css, i)
+			if end < 0 {
+				return css[:i], css[i+1:], true, ""
+			}
+			return css[:i], css[i+1 : end], true, css[end+1:]
+		}
+	}
+	return css, "", false, ""
+}
+
+// matchingBrace returns the index of the brace that closes the one at open,
+// or -1 if there is none.
+func matchingBrace(css string, open int) int {
+	depth := 0
+	for i := open; i < len(css); i++ {
+		switch css[i] {
+		case '"', '\'':
+			i = skipString(css, i)
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// skipString returns the index of the quote that ends the string starting at
+// i, or the end of css if it's unterminated.
+func skipString(css string, i int) int {
+	quote := css[i]
+	for i++; i < len(css) && css[i] != quote; i++ {
+		if css[i] == '\\' {
+			i++
+		}
+	}
+	return i
+}
+
+// stripComments returns css without its comments.
+func stripComments(css string) string {
+	var sb strings.Builder
+	for i := 0; i < len(css); i++ {
+		switch {
+		case css[i] == '"' || css[i] == '\'':
+			end := skipString(css, i)
+			if end >= len(css) {
+				end = len(css) - 1
+			}
+			sb.WriteString(css[i : end+1])
+			i = end
+		case strings.HasPrefix(css[i:], "/*"):
+			end := strings.Index(css[i+2:], "*/")
+			if end < 0 {
+				return sb.String()
+			}
+			i += end + 3
+		default:
+			sb.WriteByte(css[i])
+		}
+	}
+	return sb.String()
+}
+
+// splitList splits a selector list or a block of declarations at the
+// separators, i.e. commas or semicolons, outside brackets, parentheses and
+// strings.
+func splitList(list string, sep byte) (items []string) {
+	depth, start := 0, 0
+	for i := 0; i < len(list); i++ {
+		switch list[i] {
+		case '"', '\'':
+			i = skipString(list, i)
+		case '\\':
+			i++
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+		case sep:
+			if depth == 0 {
+				items = append(items, strings.TrimSpace(list[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(items, strings.TrimSpace(list[start:]))
+}
+
+// mayMatch reports whether u has all the tags, classes and ids selector
+// names outside brackets and parentheses.
+func (u *usedNames) mayMatch(selector string) bool {
+	compoundStart := true // whether a type selector may start at i
+	depth := 0
+	for i := 0; i < len(selector); i++ {
+		c := selector[i]
+		switch {
+		case c == '"' || c == '\'':
+			i = skipString(selector, i)
+			continue
+		case c == '(' || c == '[':
+			depth++
+			continue
+		case c == ')' || c == ']':
+			depth--
+			continue
+		case depth > 0:
+			continue
+		}
+		switch {
+		case c == '.' || c == '#':
+			name, next := readIdent(selector, i+1)
+			i = next - 1
+			if c == '.' && !u.classes[name] && !u.words[name] ||
+				c == '#' && !u.ids[name] && !u.words[name] {
+				return false
+			}
+		case c == ':':
+			for i+1 < len(selector) && selector[i+1] == ':' {
+				i++
+			}
+			_, next := readIdent(selector, i+1)
+			i = next - 1
+		case compoundStart && (isIdentByte(c) || c == '\\'):
+			name, next := readIdent(selector, i)
+			i = next - 1
+			if !u.tags[strings.ToLower(name)] {
+				return false
+			}
+		}
+		compoundStart = strings.IndexByte(" \t\n>+~", c) >= 0
+	}
+	return true
+}
+
+// readIdent returns the CSS identifier starting at i, with any backslash
+// escapes removed, and the index of the byte following it.
+func readIdent(s string, i int) (name string, next int) {
+	var sb strings.Builder
+	for ; i < len(s); i++ {
+		c := s[i]
+		if c == '\\' && i+1 < len(s) {
+			i++
+			sb.WriteByte(s[i])
+			continue
+		}
+		if !isIdentByte(c) {
+			break
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String(), i
+}
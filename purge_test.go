@@ -0,0 +1,130 @@
+package gohtx
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPurgeCSS(t *testing.T) {
+	css := `@charset "utf-8";
+/* .box { } */
+html, .unused { margin: 0 }
+.box, .card { padding: 1rem }
+div.box > p, em.box { color: red }
+.button.is-primary:hover:not(.is-loading), .button.is-danger { color: blue }
+.menu[data-x=".box"] { top: 0 }
+#main .title, #other { font-size: 2em }
+:root { --accent: #00d1b2 }
+.toggled { display: block }
+.flash { opacity: 0 }
+.htmx-indicator { opacity: 0 }
+@media screen and (min-width: 769px) { .box { width: 50% } .unused { width: 0 } }
+@media print { .unused { display: none } }
+@keyframes spin { from { transform: rotate(0) } to { transform: rotate(359deg) } }
+`
+	page := Div(`id="main" class="box"`,
+		P(`class="title"`, "Hello"),
+		Button(`class="button is-primary" _="on click toggle .toggled on me"`, "Go"),
+		Raw(`<span class="menu">raw</span>`),
+	)
+	exp := `@charset "utf-8";
+html{ margin: 0 }
+.box{ padding: 1rem }
+div.box > p{ color: red }
+.button.is-primary:hover:not(.is-loading){ color: blue }
+.menu[data-x=".box"]{ top: 0 }
+#main .title{ font-size: 2em }
+:root{ --accent: #00d1b2 }
+.toggled{ display: block }
+.flash{ opacity: 0 }
+.htmx-indicator{ opacity: 0 }
+@media screen and (min-width: 769px){.box{ width: 50% }}
+@keyframes spin{ from { transform: rotate(0) } to { transform: rotate(359deg) } }
+`
+	if got := PurgeCSS(css, []*HtmlTree{page}, "flash"); got != exp {
+		t.Errorf("expected\n%s\ngot\n%s", exp, got)
+	}
+}
+
+func TestPurgeBulma(t *testing.T) {
+	page := Html(``, Head(``, DefaultHeadContent()),
+		Body(``, Div(`class="container"`, Button(`class="button is-primary"`, "Go"))))
+	got := PurgeBulma([]*HtmlTree{page})
+	full, _ := staticFiles.ReadFile("bulma/css/bulma.min.css")
+	if len(got) > len(full)/10 {
+		t.Errorf("expected a purged stylesheet under a tenth of Bulma's %d bytes, got %d", len(full), len(got))
+	}
+	for _, want := range []string{".button.is-primary{", ".container{", "body{", "@keyframes spinAround"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected the purged stylesheet to contain %s", want)
+		}
+	}
+	for _, unwanted := range []string{".navbar", ".is-danger", ".columns"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("expected the purged stylesheet not to contain %s", unwanted)
+		}
+	}
+
+	// Fragments sent later, like out-of-band swaps, are scanned when passed.
+	fragment, err := NewOOBResponse(P(``, "saved")).
+		OOB(Div(`id="flash" class="notification is-danger"`, "quota exceeded"), SwapOuterHTML, "").
+		Tree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = PurgeBulma([]*HtmlTree{page, fragment})
+	if !strings.Contains(got, ".notification.is-danger{") {
+		t.Errorf("expected the rules for the fragment's classes to be kept")
+	}
+}
+
+func TestAddAsset(t *testing.T) {
+	css := PurgeCSS(".box{padding:1rem}", []*HtmlTree{Div(`class="box"`)})
+	if err := AddAsset("test/purged.css", []byte(css)); err != nil {
+		t.Fatal(err)
+	}
+	assets := NewAssets("/static/")
+	url := assets.URL("test/purged.css")
+	if !strings.HasPrefix(url, "/static/test/purged.") || url == "/static/test/purged.css" {
+		t.Errorf("expected a fingerprinted URL, got %s", url)
+	}
+	r := httptest.NewRequest("GET", url, nil)
+	w := httptest.NewRecorder()
+	assets.ServeHTTP(w, r)
+	if w.Code != http.StatusOK || w.Body.String() != css || !strings.Contains(w.Header().Get("Cache-Control"), "immutable") {
+		t.Errorf("unexpected response %d %q %v", w.Code, w.Body.String(), w.Header())
+	}
+	head, err := assets.BuildHeadContent(HeadOptions{Stylesheets: []string{"test/purged.css"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := Render(head, &buf, -1); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); !strings.Contains(got, `href="`+url+`" integrity="sha384-`) {
+		t.Errorf("expected the head content to link %s with its integrity hash, got %s", url, got)
+	}
+
+	// Replacing the content keeps the old URL working.
+	if err := AddAsset("test/purged.css", []byte(".card{}")); err != nil {
+		t.Fatal(err)
+	}
+	if assets.URL("test/purged.css") == url {
+		t.Errorf("expected a new fingerprint")
+	}
+	w = httptest.NewRecorder()
+	assets.ServeHTTP(w, httptest.NewRequest("GET", url, nil))
+	if w.Code != http.StatusOK || w.Body.String() != css {
+		t.Errorf("expected the old URL to serve the old content, got %d %q", w.Code, w.Body.String())
+	}
+
+	for _, name := range []string{"htmx.min.js", "test/purged.exe", "../purged.css", "/purged.css", "test/../purged.css"} {
+		if err := AddAsset(name, nil); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
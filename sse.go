@@ -0,0 +1,269 @@
+package gohtx
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SSEBroker is an http.Handler that streams Server-Sent Events to htmx pages
+// using the sse extension. Each request to it opens a stream subscribed to
+// the topics returned by Topics. Fragments published to a topic are rendered
+// once and sent, as named events, to every stream subscribed to it. E.g.
+//
+//	broker := NewSSEBroker()
+//	mux.Handle("/events", broker)
+//	...
+//	Div(``, Hx.SSEConnect("/events?topic=jobs"),
+//		Div(``, Hx.SSESwap("progress"), "Waiting..."))
+//	...
+//	broker.Publish("jobs", "progress", Progress(`max=100`, Attr("value", "40")))
+//
+// Each event is numbered. Browsers send the number of the last event they saw
+// in the Last-Event-ID header when they reconnect, and the events after it are
+// replayed from the last History events kept by the broker. Streams are
+// removed when their clients disconnect. A client that falls too far behind
+// is disconnected rather than slowing down the others, and catches up on
+// reconnecting.
+type SSEBroker struct {
+	// Topics returns the topics a request subscribes to. If nil, the values
+	// of the topic query parameter are used, e.g. /events?topic=a&topic=b.
+	// An error wrapping an HTTPError, e.g. from Errorf, rejects the request
+	// with its status.
+	Topics func(r *http.Request) ([]string, error)
+
+	// Heartbeat is the interval between the comments sent to keep idle
+	// streams from being closed by proxies. Zero disables heartbeats.
+	Heartbeat time.Duration
+
+	// Retry, if not zero, is sent to clients as the time to wait before
+	// reconnecting.
+	Retry time.Duration
+
+	// History is the number of events kept for replay to reconnecting
+	// clients.
+	History int
+
+	mu      sync.Mutex
+	clients map[*sseClient]bool
+	lastID  uint64
+	history []sseEvent
+	closed  bool
+}
+
+// sseClient is a stream subscribed to topics.
+type sseClient struct {
+	topics map[string]bool
+	frames chan []byte // closed when the broker drops the client
+}
+
+// sseEvent is an encoded event kept for replay.
+type sseEvent struct {
+	id    uint64
+	topic string
+	frame []byte
+}
+
+// sseClientBuffer is the number of events that may be queued for a client
+// before it's disconnected.
+const sseClientBuffer = 64
+
+// NewSSEBroker returns an SSEBroker that sends heartbeats every 15 seconds
+// and keeps the last 100 events for replay.
+func NewSSEBroker() *SSEBroker {
+	return &SSEBroker{Heartbeat: 15 * time.Second, History: 100}
+}
+
+// Publish renders h and sends it to the clients subscribed to topic as an
+// event named event. An empty event name sends an unnamed event, which the
+// browser delivers as "message". It returns an error if h can't be rendered
+// or the event name is invalid.
+func (b *SSEBroker) Publish(topic, event string, h *HtmlTree) error {
+	var buf bytes.Buffer
+	if err := Render(h, &buf, -1); err != nil {
+		return err
+	}
+	return b.PublishData(topic, event, buf.String())
+}
+
+// Broadcast is like Publish but sends the event to every client, whatever
+// its topics.
+func (b *SSEBroker) Broadcast(event string, h *HtmlTree) error {
+	return b.Publish("", event, h)
+}
+
+// PublishData sends data, as is, to the clients subscribed to topic as an
+// event named event. An empty topic sends it to every client. Data may
+// contain line breaks.
+func (b *SSEBroker) PublishData(topic, event, data string) error {
+	if strings.ContainsAny(event, "\r\n") {
+		return fmt.Errorf("sse: event name %q contains a line break", event)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lastID++
+	e := sseEvent{id: b.lastID, topic: topic, frame: sseFrame(b.lastID, event, data)}
+	if b.History > 0 {
+		if len(b.history) >= b.History {
+			b.history = append(b.history[:0], b.history[len(b.history)-b.History+1:]...)
+		}
+		b.history = append(b.history, e)
+	}
+	for c := range b.clients {
+		if !c.subscribed(topic) {
+			continue
+		}
+		select {
+		case c.frames <- e.frame:
+		default:
+			b.drop(c)
+		}
+	}
+	return nil
+}
+
+// Clients returns the number of connected clients.
+func (b *SSEBroker) Clients() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.clients)
+}
+
+// Close ends every stream and rejects new ones with 503 Service Unavailable.
+// Call it before shutting down an http.Server, whose Shutdown method waits
+// for handlers, like the streams, to return.
+func (b *SSEBroker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for c := range b.clients {
+		b.drop(c)
+	}
+}
+
+// drop removes c from b and closes its stream. b.mu must be held.
+func (b *SSEBroker) drop(c *sseClient) {
+	if b.clients[c] {
+		delete(b.clients, c)
+		close(c.frames)
+	}
+}
+
+// subscribed reports whether c receives events published to topic.
+func (c *sseClient) subscribed(topic string) bool {
+	return topic == "" || c.topics[topic]
+}
+
+// ServeHTTP streams events to the client until it disconnects or b is closed.
+func (b *SSEBroker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		writeError(w, r, Errorf(http.StatusMethodNotAllowed, "sse: method %s not allowed", r.Method))
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, r, fmt.Errorf("sse: %T can't flush", w))
+		return
+	}
+	topics := r.URL.Query()["topic"]
+	if b.Topics != nil {
+		var err error
+		if topics, err = b.Topics(r); err != nil {
+			writeError(w, r, err)
+			return
+		}
+	}
+	c := &sseClient{topics: make(map[string]bool), frames: make(chan []byte, sseClientBuffer)}
+	for _, t := range topics {
+		c.topics[t] = true
+	}
+
+	// Registering the client and collecting the events to replay under the
+	// same lock means none are missed or sent twice.
+	lastID, _ := strconv.ParseUint(r.Header.Get("Last-Event-ID"), 10, 64)
+	var replay [][]byte
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		writeError(w, r, Errorf(http.StatusServiceUnavailable, "sse: broker closed"))
+		return
+	}
+	if b.clients == nil {
+		b.clients = make(map[*sseClient]bool)
+	}
+	b.clients[c] = true
+	if r.Header.Get("Last-Event-ID") != "" {
+		for _, e := range b.history {
+			if e.id > lastID && c.subscribed(e.topic) {
+				replay = append(replay, e.frame)
+			}
+		}
+	}
+	b.mu.Unlock()
+	defer func() {
+		b.mu.Lock()
+		b.drop(c)
+		b.mu.Unlock()
+	}()
+
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("X-Accel-Buffering", "no") // keeps nginx from buffering the stream
+	w.WriteHeader(http.StatusOK)
+	if b.Retry > 0 {
+		fmt.Fprintf(w, "retry: %d\n\n", b.Retry.Milliseconds())
+	}
+	for _, frame := range replay {
+		if _, err := w.Write(frame); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	var heartbeat <-chan time.Time
+	if b.Heartbeat > 0 {
+		ticker := time.NewTicker(b.Heartbeat)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+	for {
+		var frame []byte
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat:
+			frame = []byte(":\n\n")
+		case frame, ok = <-c.frames:
+			if !ok {
+				return
+			}
+		}
+		if _, err := w.Write(frame); err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// sseFrame encodes an event. Each line of data gets its own data field, with
+// \r\n, \r and \n all taken as line breaks, as the browser does.
+func sseFrame(id uint64, event, data string) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "id: %d\n", id)
+	if event != "" {
+		fmt.Fprintf(&buf, "event: %s\n", event)
+	}
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	data = strings.ReplaceAll(data, "\r", "\n")
+	for _, line := range strings.Split(data, "\n") {
+		fmt.Fprintf(&buf, "data: %s\n", line)
+	}
+	buf.WriteString("\n")
+	return buf.Bytes()
+}
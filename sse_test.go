@@ -0,0 +1,194 @@
+package gohtx
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSSEFrame(t *testing.T) {
+	for _, test := range []struct {
+		event, data, exp string
+	}{
+		{"progress", "<b>40%</b>", "id: 7\nevent: progress\ndata: <b>40%</b>\n\n"},
+		{"", "a\nb\r\nc\rd", "id: 7\ndata: a\ndata: b\ndata: c\ndata: d\n\n"},
+		{"x", "", "id: 7\nevent: x\ndata: \n\n"},
+	} {
+		if got := string(sseFrame(7, test.event, test.data)); got != test.exp {
+			t.Errorf("expected %q, got %q", test.exp, got)
+		}
+	}
+}
+
+// sseStream reads the events of an SSE response.
+type sseStream struct {
+	t    *testing.T
+	resp *http.Response
+	r    *bufio.Reader
+}
+
+func openSSE(t *testing.T, url, lastID string) *sseStream {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lastID != "" {
+		req.Header.Set("Last-Event-ID", lastID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("unexpected status %d, Content-Type %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	return &sseStream{t: t, resp: resp, r: bufio.NewReader(resp.Body)}
+}
+
+// next returns the next frame, up to and including the blank line.
+func (s *sseStream) next() string {
+	var frame strings.Builder
+	for {
+		line, err := s.r.ReadString('\n')
+		if err != nil {
+			s.t.Fatalf("reading stream: %v after %q", err, frame.String())
+		}
+		frame.WriteString(line)
+		if line == "\n" {
+			return frame.String()
+		}
+	}
+}
+
+func (s *sseStream) close() { s.resp.Body.Close() }
+
+// waitForClients waits until b has n clients.
+func waitForClients(t *testing.T, b *SSEBroker, n int) {
+	for i := 0; b.Clients() != n; i++ {
+		if i == 200 {
+			t.Fatalf("expected %d clients, have %d", n, b.Clients())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestSSEBroker(t *testing.T) {
+	b := NewSSEBroker()
+	b.Heartbeat = 0
+	b.Retry = 2 * time.Second
+	srv := httptest.NewServer(b)
+	defer srv.Close()
+	defer b.Close()
+
+	jobs := openSSE(t, srv.URL+"?topic=jobs", "")
+	defer jobs.close()
+	if got := jobs.next(); got != "retry: 2000\n\n" {
+		t.Errorf("unexpected retry frame %q", got)
+	}
+	mail := openSSE(t, srv.URL+"?topic=mail", "")
+	defer mail.close()
+	mail.next()
+	waitForClients(t, b, 2)
+
+	if err := b.Publish("jobs", "progress", Span(``, "40 < 50")); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Broadcast("notice", Div(``, Raw("line 1\nline 2"))); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := jobs.next(), "id: 1\nevent: progress\ndata: <span>40 &lt; 50</span>\n\n"; got != exp {
+		t.Errorf("expected %q, got %q", exp, got)
+	}
+	notice := "id: 2\nevent: notice\ndata: <div>line 1\ndata: line 2</div>\n\n"
+	for _, s := range []*sseStream{jobs, mail} {
+		if got := s.next(); got != notice {
+			t.Errorf("expected %q, got %q", notice, got)
+		}
+	}
+	if err := b.PublishData("jobs", "bad\nname", "x"); err == nil {
+		t.Errorf("expected an error for an event name with a line break")
+	}
+
+	// A client reconnecting after event 1 gets the events it missed on
+	// its topics.
+	b.PublishData("mail", "count", "3")
+	b.PublishData("jobs", "progress", "100%")
+	again := openSSE(t, srv.URL+"?topic=jobs", "1")
+	defer again.close()
+	again.next() // retry
+	for _, exp := range []string{notice, "id: 4\nevent: progress\ndata: 100%\n\n"} {
+		if got := again.next(); got != exp {
+			t.Errorf("replay: expected %q, got %q", exp, got)
+		}
+	}
+
+	// Disconnected clients are removed.
+	waitForClients(t, b, 3)
+	mail.close()
+	again.close()
+	waitForClients(t, b, 1)
+
+	b.Close()
+	waitForClients(t, b, 0)
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 from a closed broker, got %d", resp.StatusCode)
+	}
+}
+
+func TestSSEBrokerHeartbeat(t *testing.T) {
+	b := NewSSEBroker()
+	b.Heartbeat = 10 * time.Millisecond
+	b.Topics = func(r *http.Request) ([]string, error) {
+		if r.URL.Query().Get("user") == "" {
+			return nil, Errorf(http.StatusForbidden, "no user")
+		}
+		return []string{"user:" + r.URL.Query().Get("user")}, nil
+	}
+	srv := httptest.NewServer(b)
+	defer srv.Close()
+	defer b.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected 403 from Topics, got %d", resp.StatusCode)
+	}
+
+	s := openSSE(t, srv.URL+"?user=ann", "")
+	defer s.close()
+	if got := s.next(); got != ":\n\n" {
+		t.Errorf("expected a heartbeat, got %q", got)
+	}
+	waitForClients(t, b, 1)
+	b.PublishData("user:ann", "", "hi")
+	for {
+		if got := s.next(); got != ":\n\n" {
+			if got != "id: 1\ndata: hi\n\n" {
+				t.Errorf("unexpected frame %q", got)
+			}
+			break
+		}
+	}
+}
+
+func TestSSEHistory(t *testing.T) {
+	b := NewSSEBroker()
+	b.History = 2
+	for i := 0; i < 5; i++ {
+		b.PublishData("", "", "x")
+	}
+	if len(b.history) != 2 || b.history[0].id != 4 || b.history[1].id != 5 {
+		t.Errorf("expected events 4 and 5 in the history, got %v", b.history)
+	}
+}
@@ -0,0 +1,751 @@
+package gohtx
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Theme restyles the embedded Bulma with an override stylesheet, so you can
+// brand an app without changing the Bulma files. Empty fields keep Bulma's
+// values. Colors are hex, e.g. "#8a4d76". The hover, active, light and dark
+// shades Bulma derives from each color, and the text color that goes on it,
+// are derived from the new one the same way. Sass derives them in HSL, so
+// the palette colors, Primary to Danger, may also be hsl(), e.g.
+// "hsl(171, 100%, 41%)", to get exactly the shades Sass would.
+//
+// The stylesheet sets CSS custom properties named --gohtx-primary,
+// --gohtx-primary-hover, --gohtx-background and so on, and overrides the Bulma
+// rules that use those values. Set Dark to change them when the browser
+// prefers a dark color scheme. Add the Theme to HeadOptions to serve its
+// stylesheet after Bulma's, or serve Theme.CSS yourself.
+//
+// A site is meant to have a small fixed set of themes, e.g. one per brand.
+// The stylesheet of each Theme served with HeadOptions is built once and kept
+// for the life of the process, so don't build themes from request data.
+type Theme struct {
+	Primary, Link, Info, Success, Warning, Danger string
+
+	Background string // the background of the page, boxes, cards and the like
+	Text       string // the color of body text
+	TextStrong string // the color of titles, labels and strong text
+
+	Family string // the font-family of the text, e.g. `"Inter", sans-serif`
+	Radius string // the border radius of buttons, inputs, boxes and the like, e.g. "8px"
+
+	// Dark holds the values to use in dark mode. Empty fields keep the values
+	// above. Its own Dark is ignored.
+	Dark *Theme
+}
+
+// themeColors lists the names of the Bulma colors a Theme can change, with
+// their values in the embedded Bulma.
+var themeColors = []struct {
+	name  string
+	bulma sassColor
+}{
+	{"primary", hsl(171, 100, 41)},
+	{"link", hsl(229, 53, 53)},
+	{"info", hsl(207, 61, 53)},
+	{"success", hsl(153, 53, 53)},
+	{"warning", hsl(44, 100, 77)},
+	{"danger", hsl(348, 86, 61)},
+}
+
+// themeShades lists the shades of a color that Bulma derives from it, named
+// by the suffix of their custom properties. The first shade that matches a
+// value in Bulma's stylesheet is the one used for it.
+var themeShades = []struct {
+	suffix string
+	derive func(c sassColor) sassColor
+}{
+	{"", func(c sassColor) sassColor { return c }},
+	{"-hover", func(c sassColor) sassColor { return c.darken(2.5) }},
+	{"-active", func(c sassColor) sassColor { return c.darken(5) }},
+	{"-text-hover", func(c sassColor) sassColor { return c.darken(10) }},
+	{"-focus", func(c sassColor) sassColor { return c.withAlpha(.25) }},
+	{"-light", func(c sassColor) sassColor { return c.light() }},
+	{"-light-hover", func(c sassColor) sassColor { return c.light().darken(2.5) }},
+	{"-light-active", func(c sassColor) sassColor { return c.light().darken(5) }},
+	{"-light-text-hover", func(c sassColor) sassColor { return c.light().darken(10) }},
+	{"-dark", func(c sassColor) sassColor { return c.dark() }},
+	{"-dark-text-hover", func(c sassColor) sassColor { return c.dark().lighten(10) }},
+	{"-invert", func(c sassColor) sassColor { return c.invert() }},
+	{"-invert-hover", func(c sassColor) sassColor { return c.invert().darken(5) }},
+	{"-invert-90", func(c sassColor) sassColor { return c.invert().withAlpha(.9) }},
+	{"-invert-70", func(c sassColor) sassColor { return c.invert().withAlpha(.7) }},
+	{"-bold-start", func(c sassColor) sassColor { return c.adjustHue(-10).saturate(10).darken(10) }},
+	{"-bold-end", func(c sassColor) sassColor { return c.adjustHue(10).saturate(5).lighten(5) }},
+}
+
+// Bulma's values for the other Theme fields. Its font family is read from
+// the stylesheet.
+const (
+	bulmaBackground = "#fff"
+	bulmaText       = "#4a4a4a"
+	bulmaTextStrong = "#363636"
+	bulmaRadius     = "4px"
+)
+
+// CSS returns the override stylesheet for t. It returns an error if a color
+// isn't hex, or hsl() for the palette, or the family or radius would break out
+// of their declarations.
+func (t *Theme) CSS() (string, error) {
+	rules := bulmaThemeRules() // also finds bulmaFamily
+	light, err := t.properties(nil)
+	if err != nil {
+		return "", err
+	}
+	var dark map[string]string
+	if t.Dark != nil {
+		if dark, err = t.Dark.properties(light); err != nil {
+			return "", err
+		}
+	}
+	active := make(map[string]bool) // the groups, e.g. primary or family, to override
+	for name := range light {
+		active[propertyGroup(name)] = true
+	}
+	for name := range dark {
+		active[propertyGroup(name)] = true
+	}
+
+	var sb strings.Builder
+	writeProperties(&sb, ":root", themeProperties(active), light)
+	if len(dark) > 0 {
+		var ds strings.Builder
+		writeProperties(&ds, ":root", themeProperties(active), dark)
+		sb.WriteString("@media (prefers-color-scheme:dark){" + strings.TrimSpace(ds.String()) + "}\n")
+	}
+	media := ""
+	var block strings.Builder
+	flush := func() {
+		if block.Len() > 0 {
+			if media == "" {
+				sb.WriteString(block.String())
+			} else {
+				sb.WriteString(media + "{" + block.String() + "}\n")
+			}
+			block.Reset()
+		}
+	}
+	for _, r := range rules {
+		var decls []string
+		for _, d := range r.decls {
+			if value, ok := d.value(active); ok {
+				decls = append(decls, d.property+":"+value)
+			}
+		}
+		if len(decls) == 0 {
+			continue
+		}
+		if r.media != media {
+			flush()
+			media = r.media
+		}
+		rule := r.selector + "{" + strings.Join(decls, ";") + "}"
+		if media == "" {
+			rule += "\n"
+		}
+		block.WriteString(rule)
+	}
+	flush()
+	return sb.String(), nil
+}
+
+// properties returns the custom properties, without the --gohtx- prefix,
+// for the fields of t that are set. For a dark theme, base holds the light
+// properties. Fields the light theme doesn't set are given Bulma's values,
+// and only the properties that differ from base are returned.
+func (t *Theme) properties(base map[string]string) (map[string]string, error) {
+	props := make(map[string]string)
+	colors := []string{t.Primary, t.Link, t.Info, t.Success, t.Warning, t.Danger}
+	for i, c := range themeColors {
+		if colors[i] == "" {
+			continue
+		}
+		color, err := parseThemeColor(colors[i])
+		if err != nil {
+			return nil, fmt.Errorf("theme %s: %v", c.name, err)
+		}
+		for _, shade := range themeShades {
+			props[c.name+shade.suffix] = shade.derive(color).String()
+		}
+	}
+	for _, f := range []struct{ name, value string }{
+		{"background", t.Background},
+		{"text", t.Text},
+		{"text-strong", t.TextStrong},
+	} {
+		if f.value == "" {
+			continue
+		}
+		if _, err := parseHexColor(f.value); err != nil {
+			return nil, fmt.Errorf("theme %s: %v", f.name, err)
+		}
+		props[f.name] = f.value
+	}
+	for _, f := range []struct{ name, value string }{
+		{"family", t.Family},
+		{"radius", t.Radius},
+	} {
+		if f.value == "" {
+			continue
+		}
+		if strings.ContainsAny(f.value, ";{}<>\\\n") {
+			return nil, fmt.Errorf("theme %s: %q is not a valid value", f.name, f.value)
+		}
+		props[f.name] = f.value
+	}
+	if base == nil {
+		return props, nil
+	}
+	// In dark mode, a group the light theme doesn't set starts from Bulma's
+	// value, which the light stylesheet needs too.
+	for name, value := range props {
+		if _, ok := base[name]; !ok {
+			base[name] = bulmaProperty(name)
+		}
+		if base[name] == value {
+			delete(props, name)
+		}
+	}
+	return props, nil
+}
+
+// bulmaProperty returns Bulma's value for the named custom property.
+func bulmaProperty(name string) string {
+	switch name {
+	case "background":
+		return bulmaBackground
+	case "text":
+		return bulmaText
+	case "text-strong":
+		return bulmaTextStrong
+	case "family":
+		return bulmaFamily
+	case "radius":
+		return bulmaRadius
+	}
+	for _, c := range themeColors {
+		for _, shade := range themeShades {
+			if name == c.name+shade.suffix {
+				return shade.derive(c.bulma).String()
+			}
+		}
+	}
+	return ""
+}
+
+// themeProperties returns the names of the custom properties of the active
+// groups, in the order they're written.
+func themeProperties(active map[string]bool) (names []string) {
+	for _, c := range themeColors {
+		if active[c.name] {
+			for _, shade := range themeShades {
+				names = append(names, c.name+shade.suffix)
+			}
+		}
+	}
+	for _, name := range []string{"background", "text", "text-strong", "family", "radius"} {
+		if active[name] {
+			names = append(names, name)
+		}
+	}
+	return
+}
+
+// writeProperties writes a rule for selector that sets the custom properties
+// in names that have values.
+func writeProperties(sb *strings.Builder, selector string, names []string, values map[string]string) {
+	var decls []string
+	for _, name := range names {
+		if value, ok := values[name]; ok {
+			decls = append(decls, "--gohtx-"+name+":"+value)
+		}
+	}
+	if len(decls) > 0 {
+		sb.WriteString(selector + "{" + strings.Join(decls, ";") + "}\n")
+	}
+}
+
+// propertyGroup returns the Theme field a custom property belongs to, e.g.
+// primary for primary-light-hover.
+func propertyGroup(name string) string {
+	for _, c := range themeColors {
+		if strings.HasPrefix(name, c.name) {
+			return c.name
+		}
+	}
+	return name
+}
+
+// themeAssets holds the asset names of the Themes served so far, so the
+// stylesheet of each is only built once.
+var themeAssets = struct {
+	sync.Mutex
+	names map[themeKey]string
+}{names: make(map[themeKey]string)}
+
+// themeKey identifies a Theme by its values, including those of its Dark
+// Theme, so changing a Theme after it's served gives it a new stylesheet.
+type themeKey struct {
+	light, dark Theme
+	hasDark     bool
+}
+
+// themeAsset adds the stylesheet for t to the assets, if it isn't there
+// already, and returns its name. The name is derived from the content, so
+// each Theme gets its own URL. Stylesheets are kept for the life of the
+// process, so themes should be a small fixed set, not built from request
+// data.
+func themeAsset(t *Theme) (string, error) {
+	key := themeKey{light: *t}
+	key.light.Dark = nil
+	if t.Dark != nil {
+		key.dark, key.hasDark = *t.Dark, true
+		key.dark.Dark = nil
+	}
+	themeAssets.Lock()
+	defer themeAssets.Unlock()
+	if name, found := themeAssets.names[key]; found {
+		return name, nil
+	}
+	css, err := t.CSS()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(css))
+	name := "theme/" + hex.EncodeToString(sum[:6]) + ".css"
+	if _, found := lookupAsset(name); !found {
+		if err := AddAsset(name, []byte(css)); err != nil {
+			return "", err
+		}
+	}
+	themeAssets.names[key] = name
+	return name, nil
+}
+
+// themeRule holds the declarations of a Bulma rule that a Theme can
+// override.
+type themeRule struct {
+	media    string // the prelude of the enclosing @media rule, or ""
+	selector string
+	decls    []themeDecl
+}
+
+// themeDecl is a declaration whose value is made of literal text and values
+// a Theme can override.
+type themeDecl struct {
+	property string
+	parts    []themePart
+}
+
+// themePart is literal text in a declaration's value, or a value that's
+// replaced by the named custom property when its group is overridden.
+type themePart struct {
+	text  string // the text in Bulma's stylesheet
+	name  string // the custom property, without the --gohtx- prefix, or ""
+	group string // the Theme field name belongs to
+}
+
+// value returns the value of d with the custom properties of the active
+// groups, and whether any were used.
+func (d themeDecl) value(active map[string]bool) (string, bool) {
+	var sb strings.Builder
+	used := false
+	for _, p := range d.parts {
+		if p.name != "" && active[p.group] {
+			sb.WriteString("var(--gohtx-" + p.name + ")")
+			used = true
+		} else {
+			sb.WriteString(p.text)
+		}
+	}
+	return sb.String(), used
+}
+
+var (
+	themeRulesOnce sync.Once
+	themeRules     []themeRule
+	bulmaFamily    string // Bulma's $family-primary
+)
+
+// bulmaThemeRules returns the rules of the embedded Bulma that a Theme can
+// override. They're found the first time they're needed.
+func bulmaThemeRules() []themeRule {
+	themeRulesOnce.Do(func() {
+		css, err := staticFiles.ReadFile("bulma/css/bulma.min.css")
+		if err != nil {
+			// The FS is compiled in, so failure is a programming error.
+			panic(err)
+		}
+		themeRules = findThemeRules(stripComments(string(css)), "")
+	})
+	return themeRules
+}
+
+// colorToken matches the colors in a declaration's value.
+var colorToken = regexp.MustCompile(`#[0-9a-fA-F]{6}\b|#[0-9a-fA-F]{3}\b|rgba?\([^)]*\)`)
+
+// colorSelector matches the modifiers of the colors a Theme can change,
+// e.g. is-primary or has-text-primary-light. otherColorSelector matches the
+// modifiers of the colors it can't, which aren't overridden at all.
+var (
+	colorSelector      = regexp.MustCompile(`(?:^|[^\w-])(?:is|has-text|has-background)-(primary|link|info|success|warning|danger)(?:-light|-dark)?(?:[^\w-]|$)`)
+	otherColorSelector = regexp.MustCompile(`(?:^|[^\w-])(?:is|has-text|has-background)-(white|black|light|dark|grey[\w-]*)(?:[^\w-]|$)`)
+)
+
+// findThemeRules returns the rules of css, inside the given @media rule, with
+// declarations a Theme can override.
+func findThemeRules(css, media string) (rules []themeRule) {
+	for len(css) > 0 {
+		prelude, block, isBlock, rest := nextRule(css)
+		css = rest
+		prelude = strings.TrimSpace(prelude)
+		switch {
+		case !isBlock:
+		case strings.HasPrefix(prelude, "@media"):
+			rules = append(rules, findThemeRules(block, prelude)...)
+		case strings.HasPrefix(prelude, "@"):
+		default:
+			r := themeRule{media: media, selector: prelude}
+			for _, decl := range splitList(block, ';') {
+				if d, ok := themeDeclaration(prelude, decl); ok {
+					r.decls = append(r.decls, d)
+				}
+			}
+			if len(r.decls) > 0 {
+				rules = append(rules, r)
+			}
+		}
+	}
+	return
+}
+
+// themeDeclaration returns decl, from a rule for selector, split into the
+// parts a Theme can override, and whether it has any.
+func themeDeclaration(selector, decl string) (d themeDecl, ok bool) {
+	i := strings.IndexByte(decl, ':')
+	if i < 0 {
+		return
+	}
+	d.property = strings.TrimSpace(decl[:i])
+	value := strings.TrimSpace(decl[i+1:])
+	color := ""
+	if m := colorSelector.FindStringSubmatch(selector); m != nil {
+		color = m[1]
+	} else if otherColorSelector.MatchString(selector) {
+		return
+	}
+
+	switch {
+	case d.property == "font-family" && bulmaFamily == "" && strings.Contains(selector, "body"):
+		bulmaFamily = value
+		fallthrough
+	case d.property == "font-family" && value == bulmaFamily:
+		d.parts = []themePart{{text: value, name: "family", group: "family"}}
+		return d, true
+	case strings.Contains(d.property, "radius"):
+		for i, token := range strings.Fields(value) {
+			if i > 0 {
+				d.parts = append(d.parts, themePart{text: " "})
+			}
+			if token == bulmaRadius {
+				d.parts = append(d.parts, themePart{text: token, name: "radius", group: "radius"})
+				ok = true
+			} else {
+				d.parts = append(d.parts, themePart{text: token})
+			}
+		}
+		return
+	}
+
+	last := 0
+	for _, loc := range colorToken.FindAllStringIndex(value, -1) {
+		token := value[loc[0]:loc[1]]
+		name := themeColorName(d.property, color, token)
+		if name == "" {
+			continue
+		}
+		d.parts = append(d.parts,
+			themePart{text: value[last:loc[0]]},
+			themePart{text: token, name: name, group: propertyGroup(name)})
+		last = loc[1]
+		ok = true
+	}
+	d.parts = append(d.parts, themePart{text: value[last:]})
+	return
+}
+
+// themeColorName returns the custom property for token, a color in the
+// value of property in a rule for the given Theme color, or in a rule for no
+// color if color is empty. It returns "" if a Theme doesn't change token.
+func themeColorName(property, color, token string) string {
+	c, err := parseCSSColor(token)
+	if err != nil {
+		return ""
+	}
+	if color != "" {
+		for _, shade := range bulmaShades(color) {
+			if shade.color.String() == c.String() {
+				return color + shade.suffix
+			}
+		}
+		return ""
+	}
+	// Outside the color modifiers, the link color is used for links and
+	// focus rings.
+	for _, shade := range bulmaShades("link") {
+		if shade.color.String() == c.String() && (shade.suffix == "" || shade.suffix == "-focus") {
+			return "link" + shade.suffix
+		}
+	}
+	for _, f := range []struct{ property, name, bulma string }{
+		{"background-color", "background", bulmaBackground},
+		{"color", "text", bulmaText},
+		{"color", "text-strong", bulmaTextStrong},
+	} {
+		if bulma, _ := parseHexColor(f.bulma); property == f.property && c.String() == bulma.String() {
+			return f.name
+		}
+	}
+	return ""
+}
+
+// bulmaShades returns the shades of the named Bulma color.
+func bulmaShades(name string) (shades []struct {
+	suffix string
+	color  sassColor
+}) {
+	for _, c := range themeColors {
+		if c.name != name {
+			continue
+		}
+		for _, shade := range themeShades {
+			shades = append(shades, struct {
+				suffix string
+				color  sassColor
+			}{shade.suffix, shade.derive(c.bulma)})
+		}
+	}
+	return
+}
+
+// sassColor is a color held, as Sass holds it, as hue in degrees, saturation
+// and lightness in percent, and alpha between 0 and 1. Its methods derive
+// colors the way Sass and Bulma's functions do. Bulma's colors are defined
+// in HSL, and their shades are only exact if they're derived from it.
+type sassColor struct {
+	h, s, l, a float64
+}
+
+// hsl returns the opaque color with the given hue, saturation and lightness.
+func hsl(h, s, l float64) sassColor {
+	return sassColor{h, s, l, 1}
+}
+
+// rgba returns the color with the given channels and alpha.
+func rgba(r, g, b int, a float64) sassColor {
+	rf, gf, bf := float64(r)/255, float64(g)/255, float64(b)/255
+	max, min := math.Max(rf, math.Max(gf, bf)), math.Min(rf, math.Min(gf, bf))
+	delta := max - min
+	var h, s float64
+	switch {
+	case delta == 0:
+	case max == rf:
+		h = math.Mod(60*(gf-bf)/delta, 360)
+	case max == gf:
+		h = 60*(bf-rf)/delta + 120
+	default:
+		h = 60*(rf-gf)/delta + 240
+	}
+	if h < 0 {
+		h += 360
+	}
+	l := (max + min) / 2
+	switch {
+	case delta == 0:
+	case l < .5:
+		s = delta / (max + min)
+	default:
+		s = delta / (2 - max - min)
+	}
+	return sassColor{h, s * 100, l * 100, a}
+}
+
+// parseHexColor parses #rgb or #rrggbb.
+func parseHexColor(s string) (sassColor, error) {
+	hexDigits := strings.TrimPrefix(s, "#")
+	if len(hexDigits) == 3 {
+		hexDigits = string([]byte{hexDigits[0], hexDigits[0], hexDigits[1], hexDigits[1], hexDigits[2], hexDigits[2]})
+	}
+	v, err := strconv.ParseUint(hexDigits, 16, 32)
+	if !strings.HasPrefix(s, "#") || len(hexDigits) != 6 || err != nil {
+		return sassColor{}, fmt.Errorf("%q is not a hex color", s)
+	}
+	return rgba(int(v>>16), int(v>>8&0xff), int(v&0xff), 1), nil
+}
+
+// parseThemeColor parses a palette color of a Theme, which is hex or, as
+// Bulma defines its own, hsl(), e.g. "hsl(171, 100%, 41%)".
+func parseThemeColor(s string) (sassColor, error) {
+	if !strings.HasPrefix(s, "hsl(") || !strings.HasSuffix(s, ")") {
+		return parseHexColor(s)
+	}
+	args := strings.Split(s[len("hsl("):len(s)-1], ",")
+	if len(args) != 3 {
+		return sassColor{}, fmt.Errorf("%q is not an hsl() color", s)
+	}
+	var v [3]float64
+	for i, arg := range args {
+		arg = strings.TrimSpace(arg)
+		if i > 0 && !strings.HasSuffix(arg, "%") {
+			return sassColor{}, fmt.Errorf("%q is not an hsl() color", s)
+		}
+		f, err := strconv.ParseFloat(strings.TrimSuffix(arg, "%"), 64)
+		if err != nil || i > 0 && (f < 0 || f > 100) {
+			return sassColor{}, fmt.Errorf("%q is not an hsl() color", s)
+		}
+		v[i] = f
+	}
+	return hsl(v[0], v[1], v[2]), nil
+}
+
+// parseCSSColor parses a hex, rgb() or rgba() color.
+func parseCSSColor(s string) (sassColor, error) {
+	if strings.HasPrefix(s, "#") {
+		return parseHexColor(s)
+	}
+	open, end := strings.IndexByte(s, '('), strings.IndexByte(s, ')')
+	if open < 0 || end < open {
+		return sassColor{}, fmt.Errorf("%q is not a color", s)
+	}
+	args := strings.Split(s[open+1:end], ",")
+	if len(args) != 3 && len(args) != 4 {
+		return sassColor{}, fmt.Errorf("%q is not a color", s)
+	}
+	v := [4]float64{3: 1}
+	for i, arg := range args {
+		f, err := strconv.ParseFloat(strings.TrimSpace(arg), 64)
+		if err != nil {
+			return sassColor{}, fmt.Errorf("%q is not a color", s)
+		}
+		v[i] = f
+	}
+	return rgba(int(v[0]), int(v[1]), int(v[2]), v[3]), nil
+}
+
+// rgb returns the channels of c, rounded like Sass rounds them.
+func (c sassColor) rgb() (r, g, b int) {
+	h := math.Mod(c.h, 360)
+	if h < 0 {
+		h += 360
+	}
+	h /= 360
+	s := math.Max(0, math.Min(100, c.s)) / 100
+	l := math.Max(0, math.Min(100, c.l)) / 100
+	var m2 float64
+	if l <= .5 {
+		m2 = l * (s + 1)
+	} else {
+		m2 = l + s - l*s
+	}
+	m1 := l*2 - m2
+	channel := func(h float64) int {
+		if h < 0 {
+			h++
+		}
+		if h > 1 {
+			h--
+		}
+		var v float64
+		switch {
+		case h*6 < 1:
+			v = m1 + (m2-m1)*h*6
+		case h*2 < 1:
+			v = m2
+		case h*3 < 2:
+			v = m1 + (m2-m1)*(2.0/3-h)*6
+		default:
+			v = m1
+		}
+		// Sass treats values within 1e-11 of .5 as .5.
+		return int(math.Floor(v*255 + .5 + 1e-11))
+	}
+	return channel(h + 1.0/3), channel(h), channel(h - 1.0/3)
+}
+
+// String returns c in hex, or as rgba() if it's translucent.
+func (c sassColor) String() string {
+	r, g, b := c.rgb()
+	if c.a == 1 {
+		return fmt.Sprintf("#%02x%02x%02x", r, g, b)
+	}
+	return fmt.Sprintf("rgba(%d,%d,%d,%s)", r, g, b, strconv.FormatFloat(c.a, 'f', -1, 64))
+}
+
+func (c sassColor) darken(amount float64) sassColor {
+	c.l = math.Max(0, c.l-amount)
+	return c
+}
+
+func (c sassColor) lighten(amount float64) sassColor {
+	c.l = math.Min(100, c.l+amount)
+	return c
+}
+
+func (c sassColor) saturate(amount float64) sassColor {
+	c.s = math.Min(100, c.s+amount)
+	return c
+}
+
+func (c sassColor) adjustHue(degrees float64) sassColor {
+	c.h = math.Mod(c.h+degrees+360, 360)
+	return c
+}
+
+func (c sassColor) withAlpha(a float64) sassColor {
+	c.a = a
+	return c
+}
+
+// luminance is Bulma's colorLuminance.
+func (c sassColor) luminance() float64 {
+	channel := func(v int) float64 {
+		f := float64(v) / 255
+		if f < .03928 {
+			return f / 12.92
+		}
+		f = (f + .055) / 1.055
+		return f * f
+	}
+	r, g, b := c.rgb()
+	return channel(r)*.2126 + channel(g)*.7152 + channel(b)*.0722
+}
+
+// light is Bulma's findLightColor, the background of light variants.
+func (c sassColor) light() sassColor {
+	c.l = math.Max(c.l, 96)
+	return c
+}
+
+// dark is Bulma's findDarkColor, the text of light variants.
+func (c sassColor) dark() sassColor {
+	target := math.Floor(29 + (.53-c.luminance())*53 + .5)
+	c.l = math.Max(29, target)
+	return c
+}
+
+// invert is Bulma's findColorInvert, the text that goes on c.
+func (c sassColor) invert() sassColor {
+	if c.luminance() > .55 {
+		return rgba(0, 0, 0, .7)
+	}
+	return rgba(255, 255, 255, 1)
+}
@@ -0,0 +1,291 @@
+package gohtx
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// TestThemeShades checks that the shades derived from Bulma's colors are the
+// ones in its stylesheet, so that a Theme overrides all of them.
+func TestThemeShades(t *testing.T) {
+	css, err := staticFiles.ReadFile("bulma/css/bulma.min.css")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Colors that aren't derived from the color of the rule.
+	notDerived := map[string]bool{"#ededed": true, "rgba(10,10,10,.1)": true}
+	var check func(css string)
+	check = func(css string) {
+		for len(css) > 0 {
+			prelude, block, isBlock, rest := nextRule(css)
+			css = rest
+			if strings.HasPrefix(prelude, "@media") {
+				check(block)
+				continue
+			}
+			m := colorSelector.FindStringSubmatch(prelude)
+			if !isBlock || m == nil {
+				continue
+			}
+			for _, decl := range splitList(block, ';') {
+				i := strings.IndexByte(decl, ':')
+				for _, token := range colorToken.FindAllString(decl[i+1:], -1) {
+					if themeColorName(decl[:i], m[1], token) == "" && !notDerived[token] {
+						t.Errorf("%s: %s isn't a shade of %s", prelude, token, m[1])
+					}
+				}
+			}
+		}
+	}
+	check(string(css))
+}
+
+func TestThemeColors(t *testing.T) {
+	for _, test := range []struct {
+		c   sassColor
+		exp string
+	}{
+		{hsl(171, 100, 41), "#00d1b2"},
+		{hsl(171, 100, 41).darken(2.5), "#00c4a7"},
+		{hsl(171, 100, 41).light(), "#ebfffc"},
+		{hsl(171, 100, 41).dark(), "#00947e"},
+		{hsl(171, 100, 41).invert(), "#ffffff"},
+		{hsl(44, 100, 77).invert(), "rgba(0,0,0,0.7)"},
+		{hsl(229, 53, 53).withAlpha(.25), "rgba(72,95,199,0.25)"},
+	} {
+		if got := test.c.String(); got != test.exp {
+			t.Errorf("expected %s, got %s", test.exp, got)
+		}
+	}
+	for _, s := range []string{"#abc", "#AABBCC", "rgba(1, 2, 3, .5)", "rgb(1,2,3)"} {
+		if _, err := parseCSSColor(s); err != nil {
+			t.Errorf("%s: %v", s, err)
+		}
+	}
+	for _, s := range []string{"teal", "#abcd", "#ggg", "rgba(1,2)", "abcdef"} {
+		if _, err := parseCSSColor(s); err == nil {
+			t.Errorf("%s: expected an error", s)
+		}
+	}
+	if c, err := parseThemeColor("hsl(171, 100%, 41%)"); err != nil || c != hsl(171, 100, 41) {
+		t.Errorf("expected hsl(171, 100, 41), got %v, %v", c, err)
+	}
+	for _, s := range []string{"hsl(171, 100, 41%)", "hsl(171, 100%)", "hsl(0, 120%, 50%)", "hsl(a, 1%, 1%)"} {
+		if _, err := parseThemeColor(s); err == nil {
+			t.Errorf("%s: expected an error", s)
+		}
+	}
+}
+
+func TestThemeCSS(t *testing.T) {
+	if css, err := (&Theme{}).CSS(); err != nil || css != "" {
+		t.Errorf("expected no CSS for the zero Theme, got %q, %v", css, err)
+	}
+
+	theme := &Theme{
+		Primary: "#8a4d76",
+		Family:  `"Inter", sans-serif`,
+		Radius:  "8px",
+		Dark:    &Theme{Primary: "#b77ba3", Background: "#121212"},
+	}
+	css, err := theme.CSS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		":root{--gohtx-primary:#8a4d76;--gohtx-primary-hover:",
+		"--gohtx-background:#fff;--gohtx-family:\"Inter\", sans-serif;--gohtx-radius:8px}\n",
+		"@media (prefers-color-scheme:dark){:root{--gohtx-primary:#b77ba3;",
+		"--gohtx-background:#121212}}\n",
+		"\n.button.is-primary{background-color:var(--gohtx-primary);color:var(--gohtx-primary-invert)}\n",
+		"\n.button.is-primary.is-light{background-color:var(--gohtx-primary-light);color:var(--gohtx-primary-dark)}\n",
+		"\nhtml{background-color:var(--gohtx-background)}\n",
+		"\nbody,button,input,optgroup,select,textarea{font-family:var(--gohtx-family)}\n",
+		"border-radius:var(--gohtx-radius)",
+		"@media screen and (max-width:768px){",
+	} {
+		if !strings.Contains(css, want) {
+			t.Errorf("expected the CSS to contain %q", want)
+		}
+	}
+	// Only the dark values that differ from the light ones are set again.
+	dark := regexp.MustCompile(`prefers-color-scheme:dark\){:root{[^}]*}`).FindString(css)
+	if strings.Contains(dark, "--gohtx-primary-invert:") || strings.Contains(dark, "--gohtx-radius") {
+		t.Errorf("unexpected dark values in %s", dark)
+	}
+	// Nothing else is overridden.
+	for _, unwanted := range []string{"is-info", "--gohtx-link", "--gohtx-text", "#00d1b2"} {
+		if strings.Contains(css, unwanted) {
+			t.Errorf("expected the CSS not to contain %q", unwanted)
+		}
+	}
+
+	for _, bad := range []*Theme{
+		{Primary: "teal"},
+		{Text: "#12345"},
+		{Radius: "4px}body{display:none"},
+		{Dark: &Theme{Danger: "red"}},
+	} {
+		if _, err := bad.CSS(); err == nil {
+			t.Errorf("%+v: expected an error", bad)
+		}
+	}
+}
+
+func TestHeadTheme(t *testing.T) {
+	assets := NewAssets("/static/")
+	theme := &Theme{Primary: "#8a4d76"}
+
+	head, err := assets.BuildHeadContent(HeadOptions{Bulma: true, Theme: theme, Stylesheets: []string{"/app.css"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := Render(head, &buf, -1); err != nil {
+		t.Fatal(err)
+	}
+	var hrefs []string
+	for _, m := range regexp.MustCompile(`href="([^"]*)"`).FindAllStringSubmatch(buf.String(), -1) {
+		hrefs = append(hrefs, m[1])
+	}
+	if len(hrefs) != 3 || !strings.Contains(hrefs[0], "bulma.min") || !strings.HasPrefix(hrefs[1], "/static/theme/") || hrefs[2] != "/app.css" {
+		t.Fatalf("expected Bulma, the theme and app.css, got %v", hrefs)
+	}
+	w := httptest.NewRecorder()
+	assets.ServeHTTP(w, httptest.NewRequest("GET", hrefs[1], nil))
+	css, _ := theme.CSS()
+	if w.Code != http.StatusOK || w.Body.String() != css {
+		t.Errorf("expected the theme's CSS, got %d %q", w.Code, w.Body.String())
+	}
+
+	// Without Bulma, the theme follows the stylesheets.
+	head, _ = assets.BuildHeadContent(HeadOptions{Theme: theme, Stylesheets: []string{"/purged.css"}})
+	buf.Reset()
+	Render(head, &buf, -1)
+	if i, j := strings.Index(buf.String(), "/purged.css"), strings.Index(buf.String(), "/static/theme/"); i < 0 || j < i {
+		t.Errorf("expected the theme after the stylesheets, got %s", buf.String())
+	}
+
+	if _, err := assets.BuildHeadContent(HeadOptions{Theme: &Theme{Primary: "teal"}}); err == nil {
+		t.Errorf("expected an error for an invalid theme")
+	}
+}
+
+func TestThemeAssetCached(t *testing.T) {
+	theme := &Theme{Primary: "#8a4d76", Dark: &Theme{Background: "#121212"}}
+	name, err := themeAsset(theme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	allocs := testing.AllocsPerRun(10, func() {
+		if again, err := themeAsset(theme); err != nil || again != name {
+			t.Errorf("expected %s again, got %s, %v", name, again, err)
+		}
+	})
+	if allocs > 0 {
+		t.Errorf("expected the cached stylesheet to be reused, got %v allocations", allocs)
+	}
+
+	// Themes are cached by value, so a changed Theme gets a new stylesheet.
+	theme.Dark.Background = "#000000"
+	if changed, _ := themeAsset(theme); changed == name {
+		t.Errorf("expected a new stylesheet for the changed theme")
+	}
+	if same, _ := themeAsset(&Theme{Primary: "#8a4d76", Dark: &Theme{Background: "#121212"}}); same != name {
+		t.Errorf("expected an equal theme to share the stylesheet %s, got %s", name, same)
+	}
+}
+
+// TestThemeMatchesBulma checks the properties of a Theme given Bulma's own
+// colors against the values Sass compiled into bulma.min.css.
+func TestThemeMatchesBulma(t *testing.T) {
+	min, err := staticFiles.ReadFile("bulma/css/bulma.min.css")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Bulma's declarations by selector, outside media queries.
+	declarations := make(map[string]map[string]string)
+	for css := string(min); len(css) > 0; {
+		prelude, block, isBlock, rest := nextRule(css)
+		css = rest
+		if !isBlock || strings.HasPrefix(prelude, "@") {
+			continue
+		}
+		for _, selector := range splitList(prelude, ',') {
+			if declarations[selector] == nil {
+				declarations[selector] = make(map[string]string)
+			}
+			for _, decl := range splitList(block, ';') {
+				if i := strings.IndexByte(decl, ':'); i > 0 {
+					declarations[selector][decl[:i]] = strings.TrimSuffix(decl[i+1:], "!important")
+				}
+			}
+		}
+	}
+	// Where Bulma uses each shade of color c, and which color token of the
+	// value it is.
+	shades := []struct {
+		suffix, selector, property string
+		token                      int
+	}{
+		{"", ".button.is-%s", "background-color", 0},
+		{"-hover", ".button.is-%s:hover", "background-color", 0},
+		{"-active", ".button.is-%s:active", "background-color", 0},
+		{"-text-hover", "a.has-text-%s:hover", "color", 0},
+		{"-focus", ".button.is-%s:focus:not(:active)", "box-shadow", 0},
+		{"-light", ".button.is-%s.is-light", "background-color", 0},
+		{"-light-hover", ".button.is-%s.is-light:hover", "background-color", 0},
+		{"-light-active", ".button.is-%s.is-light:active", "background-color", 0},
+		{"-dark", ".button.is-%s.is-light", "color", 0},
+		{"-dark-text-hover", "a.has-text-%s-dark:hover", "color", 0},
+		{"-invert", ".button.is-%s", "color", 0},
+		{"-invert-hover", ".button.is-%s.is-inverted:hover", "background-color", 0},
+		{"-invert-90", ".hero.is-%s .subtitle", "color", 0},
+		{"-bold-start", ".hero.is-%s.is-bold", "background-image", 0},
+		{"-bold-end", ".hero.is-%s.is-bold", "background-image", 2},
+	}
+	root := regexp.MustCompile(`^:root\{([^}]*)\}`)
+	for _, c := range themeColors {
+		color := fmt.Sprintf("hsl(%g, %g%%, %g%%)", c.bulma.h, c.bulma.s, c.bulma.l)
+		theme := &Theme{}
+		*map[string]*string{
+			"primary": &theme.Primary, "link": &theme.Link, "info": &theme.Info,
+			"success": &theme.Success, "warning": &theme.Warning, "danger": &theme.Danger,
+		}[c.name] = color
+		css, err := theme.CSS()
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := root.FindStringSubmatch(css)
+		if m == nil {
+			t.Fatalf("%s: no :root properties in %s", c.name, css)
+		}
+		props := make(map[string]string)
+		for _, decl := range splitList(m[1], ';') {
+			if i := strings.IndexByte(decl, ':'); i > 0 {
+				props[decl[:i]] = decl[i+1:]
+			}
+		}
+		for _, shade := range shades {
+			selector := fmt.Sprintf(shade.selector, c.name)
+			tokens := colorToken.FindAllString(declarations[selector][shade.property], -1)
+			if len(tokens) <= shade.token {
+				t.Errorf("%s: no color in Bulma's %s of %s", c.name, shade.property, selector)
+				continue
+			}
+			bulma, err := parseCSSColor(tokens[shade.token])
+			if err != nil {
+				t.Fatal(err)
+			}
+			name := "--gohtx-" + c.name + shade.suffix
+			if got := props[name]; got != bulma.String() {
+				t.Errorf("%s: expected Bulma's %s (%s of %s), got %s", name, bulma, shade.property, selector, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package gohtx
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// This file implements the server side of the WebSocket protocol, RFC 6455,
+// as far as WSHub needs it: the opening handshake, masked client frames,
+// fragmented messages, ping, pong and close. Extensions, like compression,
+// and subprotocols aren't negotiated.
+
+// websocketGUID is appended to the client's key to compute the
+// Sec-WebSocket-Accept header, RFC 6455 section 1.3.
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// Frame opcodes, RFC 6455 section 5.2.
+const (
+	wsContinuation = 0x0
+	wsText         = 0x1
+	wsBinary       = 0x2
+	wsClose        = 0x8
+	wsPing         = 0x9
+	wsPong         = 0xa
+)
+
+// Close status codes, RFC 6455 section 7.4.1, and the IANA registry.
+const (
+	wsCloseNormal          = 1000
+	wsCloseGoingAway       = 1001
+	wsCloseProtocolError   = 1002
+	wsCloseUnsupportedData = 1003
+	wsCloseInvalidPayload  = 1007
+	wsCloseTooBig          = 1009
+	wsCloseServiceRestart  = 1012
+	wsCloseTryAgainLater   = 1013
+)
+
+// wsWriteTimeout limits the time spent writing a frame to a client that
+// isn't reading.
+const wsWriteTimeout = 10 * time.Second
+
+// wsCloseError ends a connection with a close frame carrying code. It's
+// returned when a peer breaks the protocol and when it closes the connection.
+type wsCloseError struct {
+	code   int
+	reason string
+}
+
+// Error implements error.
+func (e *wsCloseError) Error() string {
+	return fmt.Sprintf("websocket: close %d: %s", e.code, e.reason)
+}
+
+// wsConn is the server side of a WebSocket connection. Reads must come from a
+// single goroutine. Writes may come from any.
+type wsConn struct {
+	conn        net.Conn
+	r           *bufio.Reader
+	w           *bufio.Writer
+	wmu         sync.Mutex    // serializes writes
+	maxSize     int64         // the largest message accepted
+	readTimeout time.Duration // the longest wait for a frame, zero for none
+}
+
+// wsUpgrade performs the opening handshake and takes over the connection of
+// r. If the handshake fails, an error response has already been sent when
+// wsUpgrade returns.
+func wsUpgrade(w http.ResponseWriter, r *http.Request) (*wsConn, error) {
+	fail := func(err error) (*wsConn, error) {
+		writeError(w, r, err)
+		return nil, err
+	}
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		return fail(Errorf(http.StatusMethodNotAllowed, "websocket: method %s not allowed", r.Method))
+	}
+	if !headerHasToken(r.Header, "Connection", "upgrade") || !headerHasToken(r.Header, "Upgrade", "websocket") {
+		return fail(Errorf(http.StatusBadRequest, "websocket: not a websocket handshake"))
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		return fail(Errorf(http.StatusUpgradeRequired, "websocket: unsupported version %q", r.Header.Get("Sec-WebSocket-Version")))
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if nonce, err := base64.StdEncoding.DecodeString(key); err != nil || len(nonce) != 16 {
+		return fail(Errorf(http.StatusBadRequest, "websocket: invalid Sec-WebSocket-Key %q", key))
+	}
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		return fail(fmt.Errorf("websocket: %T can't be hijacked", w))
+	}
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		return fail(fmt.Errorf("websocket: %v", err))
+	}
+	sum := sha1.Sum([]byte(key + websocketGUID))
+	c := &wsConn{conn: conn, r: rw.Reader, w: bufio.NewWriter(conn)}
+	conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	c.w.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(sum[:]) + "\r\n\r\n")
+	if err := c.w.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// headerHasToken reports whether the comma-separated values of the named
+// header include token, ignoring case.
+func headerHasToken(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// readFrame reads a frame and unmasks its payload.
+func (c *wsConn) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	if c.readTimeout > 0 {
+		c.conn.SetReadDeadline(time.Now().Add(c.readTimeout))
+	}
+	var head [2]byte
+	if _, err = io.ReadFull(c.r, head[:]); err != nil {
+		return
+	}
+	fin, opcode = head[0]&0x80 != 0, head[0]&0x0f
+	switch {
+	case head[0]&0x70 != 0:
+		err = &wsCloseError{wsCloseProtocolError, "reserved bits set"}
+		return
+	case head[1]&0x80 == 0:
+		err = &wsCloseError{wsCloseProtocolError, "unmasked client frame"}
+		return
+	}
+	n := int64(head[1] & 0x7f)
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(c.r, ext[:]); err != nil {
+			return
+		}
+		n = int64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(c.r, ext[:]); err != nil {
+			return
+		}
+		u := binary.BigEndian.Uint64(ext[:])
+		if u>>63 != 0 {
+			err = &wsCloseError{wsCloseProtocolError, "invalid payload length"}
+			return
+		}
+		n = int64(u)
+	}
+	switch {
+	case opcode >= wsClose && (n > 125 || !fin):
+		err = &wsCloseError{wsCloseProtocolError, "invalid control frame"}
+		return
+	case n > c.maxSize:
+		err = &wsCloseError{wsCloseTooBig, "message too big"}
+		return
+	}
+	var mask [4]byte
+	if _, err = io.ReadFull(c.r, mask[:]); err != nil {
+		return
+	}
+	payload = make([]byte, n)
+	if _, err = io.ReadFull(c.r, payload); err != nil {
+		return
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return
+}
+
+// readMessage returns the next text or binary message, joining fragmented
+// messages and answering pings on the way. It returns a wsCloseError when
+// the peer closes the connection or breaks the protocol.
+func (c *wsConn) readMessage() (opcode byte, data []byte, err error) {
+	for {
+		fin, op, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+		switch op {
+		case wsPing:
+			if err := c.writeFrame(wsPong, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case wsPong:
+			continue
+		case wsClose:
+			switch len(payload) {
+			case 0:
+				return 0, nil, &wsCloseError{wsCloseNormal, "closed by peer"}
+			case 1:
+				return 0, nil, &wsCloseError{wsCloseProtocolError, "invalid close frame"}
+			}
+			return 0, nil, &wsCloseError{int(binary.BigEndian.Uint16(payload)), "closed by peer"}
+		case wsText, wsBinary:
+			if opcode != 0 {
+				return 0, nil, &wsCloseError{wsCloseProtocolError, "expected a continuation frame"}
+			}
+			opcode = op
+		case wsContinuation:
+			if opcode == 0 {
+				return 0, nil, &wsCloseError{wsCloseProtocolError, "unexpected continuation frame"}
+			}
+		default:
+			return 0, nil, &wsCloseError{wsCloseProtocolError, fmt.Sprintf("unknown opcode %d", op)}
+		}
+		if int64(len(data)+len(payload)) > c.maxSize {
+			return 0, nil, &wsCloseError{wsCloseTooBig, "message too big"}
+		}
+		data = append(data, payload...)
+		if fin {
+			if opcode == wsText && !utf8.Valid(data) {
+				return 0, nil, &wsCloseError{wsCloseInvalidPayload, "invalid UTF-8"}
+			}
+			return opcode, data, nil
+		}
+	}
+}
+
+// writeFrame writes payload in a single, unmasked frame.
+func (c *wsConn) writeFrame(opcode byte, payload []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	header := []byte{0x80 | opcode}
+	switch n := len(payload); {
+	case n <= 125:
+		header = append(header, byte(n))
+	case n <= 0xffff:
+		header = append(header, 126, byte(n>>8), byte(n))
+	default:
+		var ext [8]byte
+		binary.BigEndian.PutUint64(ext[:], uint64(n))
+		header = append(append(header, 127), ext[:]...)
+	}
+	c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	c.w.Write(header)
+	c.w.Write(payload)
+	return c.w.Flush()
+}
+
+// writeClose writes a close frame with code.
+func (c *wsConn) writeClose(code int) error {
+	var payload [2]byte
+	binary.BigEndian.PutUint16(payload[:], uint16(code))
+	return c.writeFrame(wsClose, payload[:])
+}
@@ -0,0 +1,186 @@
+package gohtx
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// wsTestClient is a minimal WebSocket client for the tests.
+type wsTestClient struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// dialWS opens a WebSocket to the path on srv with extra request headers.
+func dialWS(t *testing.T, srv *httptest.Server, path string, header http.Header) (*wsTestClient, *http.Response) {
+	conn, err := net.Dial("tcp", strings.TrimPrefix(srv.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := http.NewRequest("GET", srv.URL+path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Connection", "keep-alive, Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if err := req.Write(conn); err != nil {
+		t.Fatal(err)
+	}
+	c := &wsTestClient{t: t, conn: conn, r: bufio.NewReader(conn)}
+	resp, err := http.ReadResponse(c.r, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	return c, resp
+}
+
+// writeFrame writes a masked frame.
+func (c *wsTestClient) writeFrame(fin bool, opcode byte, payload []byte) {
+	b0 := opcode
+	if fin {
+		b0 |= 0x80
+	}
+	frame := []byte{b0}
+	switch n := len(payload); {
+	case n <= 125:
+		frame = append(frame, 0x80|byte(n))
+	default:
+		frame = append(frame, 0x80|126, byte(n>>8), byte(n))
+	}
+	mask := []byte{1, 2, 3, 4}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	if _, err := c.conn.Write(frame); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// readFrame reads an unmasked frame.
+func (c *wsTestClient) readFrame() (opcode byte, payload []byte) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.r, head[:]); err != nil {
+		c.t.Fatalf("reading frame: %v", err)
+	}
+	n := int(head[1] & 0x7f)
+	if n == 126 {
+		var ext [2]byte
+		if _, err := io.ReadFull(c.r, ext[:]); err != nil {
+			c.t.Fatalf("reading frame: %v", err)
+		}
+		n = int(binary.BigEndian.Uint16(ext[:]))
+	}
+	payload = make([]byte, n)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		c.t.Fatalf("reading payload: %v", err)
+	}
+	return head[0] & 0x0f, payload
+}
+
+// expectClose reads frames up to a close frame and checks its code.
+func (c *wsTestClient) expectClose(code int) {
+	for {
+		op, payload := c.readFrame()
+		if op != wsClose {
+			continue
+		}
+		if got := int(binary.BigEndian.Uint16(payload)); got != code {
+			c.t.Errorf("expected close code %d, got %d", code, got)
+		}
+		return
+	}
+}
+
+func TestWSHandshake(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := wsUpgrade(w, r)
+		if err != nil {
+			return
+		}
+		c.maxSize = 1000
+		defer c.conn.Close()
+		for {
+			op, data, err := c.readMessage()
+			if err != nil {
+				if cerr, ok := err.(*wsCloseError); ok {
+					c.writeClose(cerr.code)
+				}
+				return
+			}
+			c.writeFrame(op, data) // echo
+		}
+	}))
+	defer srv.Close()
+
+	c, resp := dialWS(t, srv, "/", nil)
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("expected 101, got %d", resp.StatusCode)
+	}
+	// The example from RFC 6455 section 1.3.
+	if got := resp.Header.Get("Sec-WebSocket-Accept"); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("unexpected Sec-WebSocket-Accept %s", got)
+	}
+
+	// Pings are answered, and fragmented messages are joined.
+	c.writeFrame(true, wsPing, []byte("hi"))
+	if op, payload := c.readFrame(); op != wsPong || string(payload) != "hi" {
+		t.Errorf("expected a pong, got %d %q", op, payload)
+	}
+	long := strings.Repeat("é", 100)
+	c.writeFrame(false, wsText, []byte(long[:101])) // splits an é
+	c.writeFrame(true, wsPing, nil)                 // control frames may come between fragments
+	c.writeFrame(true, wsContinuation, []byte(long[101:]))
+	c.readFrame() // pong
+	if op, payload := c.readFrame(); op != wsText || string(payload) != long {
+		t.Errorf("expected the joined message, got %d %q", op, payload)
+	}
+
+	// Invalid UTF-8 closes the connection.
+	c.writeFrame(true, wsText, []byte{0xff})
+	c.expectClose(wsCloseInvalidPayload)
+
+	c, _ = dialWS(t, srv, "/", nil)
+	c.writeFrame(true, wsText, make([]byte, 1001))
+	c.expectClose(wsCloseTooBig)
+
+	c, _ = dialWS(t, srv, "/", nil)
+	c.writeFrame(true, wsContinuation, []byte("x"))
+	c.expectClose(wsCloseProtocolError)
+
+	c, _ = dialWS(t, srv, "/", nil)
+	c.conn.Write([]byte{0x81, 0x01, 'x'}) // unmasked
+	c.expectClose(wsCloseProtocolError)
+
+	c, _ = dialWS(t, srv, "/", nil)
+	c.writeFrame(true, wsClose, []byte{0x03, 0xe8})
+	c.expectClose(wsCloseNormal)
+
+	for _, test := range []struct {
+		header http.Header
+		status int
+	}{
+		{http.Header{"Sec-Websocket-Version": {"8"}}, http.StatusUpgradeRequired},
+		{http.Header{"Sec-Websocket-Key": {"short"}}, http.StatusBadRequest},
+		{http.Header{"Upgrade": {"h2c"}}, http.StatusBadRequest},
+	} {
+		_, resp := dialWS(t, srv, "/", test.header)
+		if resp.StatusCode != test.status {
+			t.Errorf("%v: expected status %d, got %d", test.header, test.status, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,464 @@
+package gohtx
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WSHub is an http.Handler that accepts WebSocket connections from pages using
+// the htmx ws extension. Messages sent by elements with ws-send are decoded
+// and dispatched to the function registered for the id of the element, and
+// fragments can be sent to one client, the clients in a room, or all of them.
+// The ws extension swaps each top-level element of a message in out-of-band,
+// by its id or its hx-swap-oob attribute. E.g.
+//
+//	hub := NewWSHub()
+//	hub.Handle("say", func(c *WSClient, m *WSMessage) error {
+//		return hub.SendRoom("lobby", Div(`id=messages hx-swap-oob=beforeend`,
+//			P(``, m.Values.Get("text"))))
+//	})
+//	mux.Handle("/chat", hub)
+//	...
+//	Div(``, Hx.WSConnect("/chat?room=lobby"),
+//		Div(`id=messages`),
+//		Form(`id=say`, Hx.WSSend(), Input(`name=text`)))
+//
+// The WebSocket protocol is implemented in gohtx, without compression or
+// subprotocols.
+type WSHub struct {
+	// CheckOrigin reports whether to accept a connection. If nil, requests
+	// whose Origin header names another host are rejected, so other sites
+	// can't connect with the user's cookies.
+	CheckOrigin func(r *http.Request) bool
+
+	// Rooms returns the rooms a connection joins. If nil, the values of the
+	// room query parameter are used, e.g. /chat?room=lobby. An error
+	// wrapping an HTTPError, e.g. from Errorf, rejects the connection with
+	// its status.
+	Rooms func(r *http.Request) ([]string, error)
+
+	// OnConnect and OnDisconnect, if not nil, are called when a client
+	// connects and after it's gone.
+	OnConnect    func(c *WSClient)
+	OnDisconnect func(c *WSClient)
+
+	// PingInterval is the interval between pings. Clients that don't
+	// answer, or send anything else, within two intervals are disconnected.
+	// Zero disables pings.
+	PingInterval time.Duration
+
+	// MaxMessageSize is the size, in bytes, of the largest message
+	// accepted. Clients sending larger ones are disconnected. Zero means
+	// 1 MiB.
+	MaxMessageSize int64
+
+	mu       sync.Mutex
+	handlers map[string]WSHandlerFunc
+	clients  map[*WSClient]bool
+	closed   bool
+}
+
+// WSHandlerFunc handles the messages from the elements with the id it was
+// registered for. The errors it returns are logged.
+type WSHandlerFunc func(c *WSClient, m *WSMessage) error
+
+// WSMessage is a message sent by an element with ws-send.
+type WSMessage struct {
+	// Hx holds the request headers htmx sent with the message, e.g. the
+	// id of the element in Hx.Trigger.
+	Hx HxRequest
+
+	// Header holds all the headers, including ones without a field in Hx.
+	Header http.Header
+
+	// Values holds the values of the element, or of its form, and of its
+	// hx-vals. Values that aren't strings are encoded as JSON.
+	Values url.Values
+}
+
+// ErrWSClosed is returned when sending to a client whose connection is closed.
+var ErrWSClosed = errors.New("websocket: connection closed")
+
+// wsClientBuffer is the number of messages that may be queued for a client
+// before it's disconnected.
+const wsClientBuffer = 64
+
+// wsDefaultMaxMessageSize is the size limit used when MaxMessageSize is zero.
+const wsDefaultMaxMessageSize = 1 << 20
+
+// NewWSHub returns a WSHub that pings clients every 30 seconds.
+func NewWSHub() *WSHub {
+	return &WSHub{PingInterval: 30 * time.Second}
+}
+
+// Handle registers f for the messages from the element with the given id.
+// Messages from elements without a registered id go to the function
+// registered for "", if any, and are otherwise logged and dropped.
+func (h *WSHub) Handle(id string, f WSHandlerFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.handlers == nil {
+		h.handlers = make(map[string]WSHandlerFunc)
+	}
+	h.handlers[id] = f
+}
+
+// SendRoom renders t and sends it to every client in room.
+func (h *WSHub) SendRoom(room string, t *HtmlTree) error {
+	return h.send(t, func(c *WSClient) bool { return c.InRoom(room) })
+}
+
+// Broadcast renders t and sends it to every client.
+func (h *WSHub) Broadcast(t *HtmlTree) error {
+	return h.send(t, func(*WSClient) bool { return true })
+}
+
+// send renders t once and queues it for the clients selected by to.
+func (h *WSHub) send(t *HtmlTree, to func(*WSClient) bool) error {
+	msg, err := renderWS(t)
+	if err != nil {
+		return err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for c := range h.clients {
+		if to(c) {
+			c.enqueue(msg)
+		}
+	}
+	return nil
+}
+
+// Clients returns the number of connected clients.
+func (h *WSHub) Clients() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.clients)
+}
+
+// Close disconnects every client, telling the ws extension to reconnect
+// later, and rejects new connections with 503 Service Unavailable.
+func (h *WSHub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for c := range h.clients {
+		c.closeWith(wsCloseServiceRestart)
+	}
+}
+
+// ServeHTTP upgrades the request to a WebSocket and reads messages from it
+// until the client disconnects.
+func (h *WSHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	checkOrigin := h.CheckOrigin
+	if checkOrigin == nil {
+		checkOrigin = sameOrigin
+	}
+	if !checkOrigin(r) {
+		writeError(w, r, Errorf(http.StatusForbidden, "websocket: origin %q not allowed", r.Header.Get("Origin")))
+		return
+	}
+	rooms := r.URL.Query()["room"]
+	if h.Rooms != nil {
+		var err error
+		if rooms, err = h.Rooms(r); err != nil {
+			writeError(w, r, err)
+			return
+		}
+	}
+	h.mu.Lock()
+	closed := h.closed
+	h.mu.Unlock()
+	if closed {
+		writeError(w, r, Errorf(http.StatusServiceUnavailable, "websocket: hub closed"))
+		return
+	}
+	conn, err := wsUpgrade(w, r)
+	if err != nil {
+		return // wsUpgrade has responded
+	}
+	conn.maxSize = h.MaxMessageSize
+	if conn.maxSize <= 0 {
+		conn.maxSize = wsDefaultMaxMessageSize
+	}
+	conn.readTimeout = 2 * h.PingInterval
+
+	c := &WSClient{
+		Request: r,
+		conn:    conn,
+		send:    make(chan []byte, wsClientBuffer),
+		rooms:   make(map[string]bool),
+	}
+	for _, room := range rooms {
+		c.rooms[room] = true
+	}
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		conn.writeClose(wsCloseServiceRestart)
+		conn.conn.Close()
+		return
+	}
+	if h.clients == nil {
+		h.clients = make(map[*WSClient]bool)
+	}
+	h.clients[c] = true
+	h.mu.Unlock()
+	go c.writeLoop(h.PingInterval)
+	defer func() {
+		h.mu.Lock()
+		delete(h.clients, c)
+		h.mu.Unlock()
+		c.closeWith(wsCloseGoingAway)
+		if h.OnDisconnect != nil {
+			h.OnDisconnect(c)
+		}
+	}()
+	if h.OnConnect != nil {
+		h.OnConnect(c)
+	}
+
+	for {
+		opcode, data, err := conn.readMessage()
+		if err != nil {
+			var cerr *wsCloseError
+			if errors.As(err, &cerr) {
+				c.closeWith(cerr.code)
+			}
+			return
+		}
+		if opcode != wsText {
+			c.closeWith(wsCloseUnsupportedData)
+			return
+		}
+		h.dispatch(c, data)
+	}
+}
+
+// dispatch decodes a message from c and passes it to the function registered
+// for its trigger.
+func (h *WSHub) dispatch(c *WSClient, data []byte) {
+	m, err := decodeWSMessage(data)
+	if err != nil {
+		logf("websocket %s: %v", c.Request.URL.Path, err)
+		return
+	}
+	h.mu.Lock()
+	f, ok := h.handlers[m.Hx.Trigger]
+	if !ok {
+		f, ok = h.handlers[""]
+	}
+	h.mu.Unlock()
+	if !ok {
+		logf("websocket %s: no handler for trigger %q", c.Request.URL.Path, m.Hx.Trigger)
+		return
+	}
+	if err := f(c, m); err != nil {
+		logf("websocket %s: trigger %q: %v", c.Request.URL.Path, m.Hx.Trigger, err)
+	}
+}
+
+// decodeWSMessage decodes the JSON sent by ws-send: the values of the element
+// and, under "HEADERS", the request headers.
+func decodeWSMessage(data []byte) (*WSMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("decoding ws-send message: %v", err)
+	}
+	m := &WSMessage{Header: make(http.Header), Values: make(url.Values)}
+	for name, raw := range fields {
+		var v interface{}
+		d := json.NewDecoder(bytes.NewReader(raw))
+		d.UseNumber()
+		if err := d.Decode(&v); err != nil {
+			return nil, fmt.Errorf("decoding ws-send message: %s: %v", name, err)
+		}
+		if name == "HEADERS" {
+			headers, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("decoding ws-send message: HEADERS isn't an object")
+			}
+			for header, value := range headers {
+				if value != nil {
+					m.Header.Set(header, wsValue(value))
+				}
+			}
+			continue
+		}
+		switch v := v.(type) {
+		case nil:
+		case []interface{}:
+			for _, e := range v {
+				m.Values.Add(name, wsValue(e))
+			}
+		default:
+			m.Values.Add(name, wsValue(v))
+		}
+	}
+	m.Hx = ParseHxRequest(&http.Request{Header: m.Header})
+	return m, nil
+}
+
+// wsValue returns v as a string, encoding anything but a string as JSON.
+func wsValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	buf, _ := json.Marshal(v)
+	return string(buf)
+}
+
+// renderWS renders t as a message.
+func renderWS(t *HtmlTree) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := Render(t, &buf, -1); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// sameOrigin reports whether r has no Origin header, as from a client that
+// isn't a browser, or one naming the host r was sent to.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// WSClient is a connection to a WSHub.
+type WSClient struct {
+	// Request is the request that opened the connection, e.g. to look up
+	// the session from its cookies.
+	Request *http.Request
+
+	conn *wsConn
+	send chan []byte // closed when the connection is closing
+
+	mu        sync.Mutex
+	rooms     map[string]bool
+	closed    bool
+	closeCode int
+}
+
+// Send renders t and sends it to c. It returns ErrWSClosed if c is closed.
+func (c *WSClient) Send(t *HtmlTree) error {
+	msg, err := renderWS(t)
+	if err != nil {
+		return err
+	}
+	if !c.enqueue(msg) {
+		return ErrWSClosed
+	}
+	return nil
+}
+
+// Join adds c to room.
+func (c *WSClient) Join(room string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rooms[room] = true
+}
+
+// Leave removes c from room.
+func (c *WSClient) Leave(room string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.rooms, room)
+}
+
+// InRoom reports whether c is in room.
+func (c *WSClient) InRoom(room string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rooms[room]
+}
+
+// Rooms returns the rooms c is in, sorted.
+func (c *WSClient) Rooms() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	rooms := make([]string, 0, len(c.rooms))
+	for room := range c.rooms {
+		rooms = append(rooms, room)
+	}
+	sort.Strings(rooms)
+	return rooms
+}
+
+// Close closes the connection normally, after the messages already queued
+// are sent.
+func (c *WSClient) Close() {
+	c.closeWith(wsCloseNormal)
+}
+
+// closeWith closes the connection with code unless it's already closing.
+func (c *WSClient) closeWith(code int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.closed {
+		c.closed, c.closeCode = true, code
+		close(c.send)
+	}
+}
+
+// enqueue queues msg for sending. A client whose queue is full is too slow
+// to keep up, so it's disconnected with a code telling the ws extension to
+// reconnect. Enqueue reports whether msg was queued.
+func (c *WSClient) enqueue(msg []byte) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return false
+	}
+	select {
+	case c.send <- msg:
+		return true
+	default:
+		c.closed, c.closeCode = true, wsCloseTryAgainLater
+		close(c.send)
+		return false
+	}
+}
+
+// writeLoop writes queued messages and pings to the connection until the
+// queue is closed, then sends a close frame and closes the connection.
+func (c *WSClient) writeLoop(ping time.Duration) {
+	defer c.conn.conn.Close()
+	var tick <-chan time.Time
+	if ping > 0 {
+		ticker := time.NewTicker(ping)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		var err error
+		select {
+		case msg, ok := <-c.send:
+			if !ok {
+				c.mu.Lock()
+				code := c.closeCode
+				c.mu.Unlock()
+				c.conn.writeClose(code)
+				return
+			}
+			err = c.conn.writeFrame(wsText, msg)
+		case <-tick:
+			err = c.conn.writeFrame(wsPing, nil)
+		}
+		if err != nil {
+			c.closeWith(wsCloseGoingAway)
+			return
+		}
+	}
+}
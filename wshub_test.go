@@ -0,0 +1,108 @@
+package gohtx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDecodeWSMessage(t *testing.T) {
+	m, err := decodeWSMessage([]byte(`{"text":"hi","tags":["a","b"],"n":12345678901234567890,"empty":null,
+		"HEADERS":{"HX-Request":"true","HX-Trigger":"say","HX-Trigger-Name":null,"HX-Target":"chat","HX-Current-URL":"http://x/chat"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.Hx.Request || m.Hx.Trigger != "say" || m.Hx.Target != "chat" || m.Hx.CurrentURL != "http://x/chat" || m.Hx.TriggerName != "" {
+		t.Errorf("unexpected headers %+v", m.Hx)
+	}
+	if m.Values.Get("text") != "hi" || len(m.Values["tags"]) != 2 || m.Values.Get("n") != "12345678901234567890" {
+		t.Errorf("unexpected values %v", m.Values)
+	}
+	if _, ok := m.Values["empty"]; ok {
+		t.Errorf("null values should be dropped, got %v", m.Values)
+	}
+	for _, bad := range []string{`[1]`, `{"HEADERS":"x"}`, `{`} {
+		if _, err := decodeWSMessage([]byte(bad)); err == nil {
+			t.Errorf("expected an error decoding %s", bad)
+		}
+	}
+}
+
+func TestWSHub(t *testing.T) {
+	hub := NewWSHub()
+	hub.PingInterval = 0
+	connected := make(chan *WSClient, 3)
+	disconnected := make(chan *WSClient, 3)
+	hub.OnConnect = func(c *WSClient) { connected <- c }
+	hub.OnDisconnect = func(c *WSClient) { disconnected <- c }
+	hub.Handle("say", func(c *WSClient, m *WSMessage) error {
+		room := m.Values.Get("room")
+		return hub.SendRoom(room, Div(`id="messages" hx-swap-oob="beforeend"`, P(``, m.Values.Get("text"))))
+	})
+	hub.Handle("", func(c *WSClient, m *WSMessage) error {
+		return c.Send(Span(`id="echo"`, m.Hx.Trigger))
+	})
+	srv := httptest.NewServer(hub)
+	defer srv.Close()
+	defer hub.Close()
+
+	lobby, resp := dialWS(t, srv, "/?room=lobby", http.Header{"Origin": {srv.URL}})
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("expected 101, got %d", resp.StatusCode)
+	}
+	both, _ := dialWS(t, srv, "/?room=lobby&room=games", nil)
+	games, _ := dialWS(t, srv, "/?room=games", nil)
+	for i := 0; i < 3; i++ {
+		<-connected
+	}
+	if hub.Clients() != 3 {
+		t.Errorf("expected 3 clients, have %d", hub.Clients())
+	}
+
+	lobby.writeFrame(true, wsText, []byte(`{"text":"a < b","room":"lobby","HEADERS":{"HX-Request":"true","HX-Trigger":"say"}}`))
+	exp := `<div id="messages" hx-swap-oob="beforeend"><p>a &lt; b</p></div>`
+	for _, c := range []*wsTestClient{lobby, both} {
+		if op, msg := c.readFrame(); op != wsText || string(msg) != exp {
+			t.Errorf("expected %s, got %d %s", exp, op, msg)
+		}
+	}
+	// Unregistered triggers go to the "" handler, and only to the sender.
+	games.writeFrame(true, wsText, []byte(`{"HEADERS":{"HX-Trigger":"other"}}`))
+	if _, msg := games.readFrame(); string(msg) != `<span id="echo">other</span>` {
+		t.Errorf("unexpected reply %s", msg)
+	}
+	if err := hub.Broadcast(Span(`id="clock"`, "12:00")); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []*wsTestClient{lobby, both, games} {
+		if _, msg := c.readFrame(); string(msg) != `<span id="clock">12:00</span>` {
+			t.Errorf("unexpected broadcast %s", msg)
+		}
+	}
+
+	// Binary messages aren't supported.
+	games.writeFrame(true, wsBinary, []byte{1})
+	games.expectClose(wsCloseUnsupportedData)
+	if c := <-disconnected; c.InRoom("games") != true || c.InRoom("lobby") {
+		t.Errorf("expected the games client to disconnect, got one in %v", c.Rooms())
+	}
+
+	// Other sites can't connect.
+	_, resp = dialWS(t, srv, "/", http.Header{"Origin": {"https://evil.example"}})
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected 403 for another origin, got %d", resp.StatusCode)
+	}
+
+	hub.Close()
+	lobby.expectClose(wsCloseServiceRestart)
+	both.expectClose(wsCloseServiceRestart)
+	<-disconnected
+	<-disconnected
+	if hub.Clients() != 0 {
+		t.Errorf("expected no clients after Close, have %d", hub.Clients())
+	}
+	_, resp = dialWS(t, srv, "/", nil)
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 from a closed hub, got %d", resp.StatusCode)
+	}
+}